@@ -0,0 +1,27 @@
+package crud
+
+import "github.com/spf13/cobra"
+
+var crudCmd = &cobra.Command{
+	Use:   "crud <model>",
+	Short: "Scaffold CRUD endpoints for a model",
+	Long: "Generates a controller with List, Detail, Create, Update and Delete actions, its request and\n" +
+		"response DTOs and a service for the model package internal/common/models/<model>, then\n" +
+		"regenerates the router.",
+	Example: "  godo gen crud user\n  godo gen crud user --cmd admin-api --route admin/user",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmdName, _ := cmd.Flags().GetString("cmd")
+		route, _ := cmd.Flags().GetString("route")
+		return genCrud(cmdName, args[0], route)
+	},
+}
+
+func GetCommand() *cobra.Command {
+	return crudCmd
+}
+
+func init() {
+	crudCmd.Flags().StringP("cmd", "", "", "The cmd that serves the endpoints, e.g. 'admin-api'")
+	crudCmd.Flags().StringP("route", "", "", "The controller route (default: the model package)")
+}
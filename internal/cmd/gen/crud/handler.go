@@ -0,0 +1,148 @@
+package crud
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/jiajia556/godo/internal/cmd/gen/dto"
+	genmodel "github.com/jiajia556/godo/internal/cmd/gen/model"
+	"github.com/jiajia556/godo/internal/cmd/gen/rt"
+	"github.com/jiajia556/godo/internal/service"
+	"github.com/jiajia556/godo/internal/template"
+	"github.com/jiajia556/godo/internal/utils"
+	"github.com/jiajia556/godo/templates"
+)
+
+var (
+	formatGoFiles = utils.FormatGoFiles
+	genRouter     = rt.GenRouter
+)
+
+// crudFile is a file rendered from a template.
+type crudFile struct {
+	template string
+	path     string
+}
+
+func genCrud(cmdName, modelPkg, route string) error {
+	var err error
+	if cmdName == "" {
+		cmdName, err = service.GetDefaultCmd()
+		if err != nil {
+			return fmt.Errorf("get default command: %w", err)
+		}
+	}
+	if err := service.ValidateCmdName(cmdName); err != nil {
+		return fmt.Errorf("validate command name: %w", err)
+	}
+	if !service.IsCmdExists(cmdName) {
+		return fmt.Errorf("command %q does not exist", cmdName)
+	}
+	if err := service.RequireCmdType(cmdName, service.CmdTypeAPI); err != nil {
+		return err
+	}
+	defaultRoute := route == ""
+	if defaultRoute {
+		route = modelPkg
+	}
+
+	controllerPath, controllerName, err := service.GetControllerPathAndNameByRoute(cmdName, route)
+	if err != nil {
+		return fmt.Errorf("resolve controller path: %w", err)
+	}
+	info, err := genmodel.LoadModelInfo(modelPkg)
+	if err != nil {
+		return fmt.Errorf("load model %s: %w", modelPkg, err)
+	}
+	if info.View {
+		return fmt.Errorf("model %s is a read-only view", modelPkg)
+	}
+	// The router takes the last route segment from the controller name, so
+	// only the default route is named after the model.
+	if defaultRoute {
+		controllerName = info.StructName + "Controller"
+	}
+	if err = service.ValidateControllerName(controllerName); err != nil {
+		return fmt.Errorf("validate controller name: %w", err)
+	}
+	projectName, err := service.GetProjectName()
+	if err != nil {
+		return fmt.Errorf("get project name: %w", err)
+	}
+	data, err := crudData(info, projectName, cmdName, controllerName)
+	if err != nil {
+		return err
+	}
+
+	fileName := path.Base(route) + ".go"
+	dtoPath, err := service.GetAbsPath(filepath.Join("internal", cmdName, "dto", fileName))
+	if err != nil {
+		return fmt.Errorf("resolve dto file: %w", err)
+	}
+	servicePath, err := service.GetAbsPath(filepath.Join("internal", cmdName, "service", fileName))
+	if err != nil {
+		return fmt.Errorf("resolve service file: %w", err)
+	}
+	files := []crudFile{
+		{template: "default/internal/default-api/transport/http/api/crud.templ", path: controllerPath},
+		{template: dto.TemplatePath, path: dtoPath},
+		{template: "default/internal/default-api/service/crud.go.templ", path: servicePath},
+	}
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		if utils.IsFileExists(file.path) {
+			return fmt.Errorf("file already exists: %s", file.path)
+		}
+		paths = append(paths, file.path)
+	}
+
+	for _, file := range files {
+		if err = os.MkdirAll(filepath.Dir(file.path), 0o755); err != nil {
+			return fmt.Errorf("create directory for %s: %w", file.path, err)
+		}
+		tmplContent, err := templates.TemplateFS.ReadFile(file.template)
+		if err != nil {
+			return fmt.Errorf("read template %s: %w", file.template, err)
+		}
+		if err = template.CreateFile(string(tmplContent), data, file.path); err != nil {
+			return fmt.Errorf("write %s: %w", file.path, err)
+		}
+	}
+	if err = formatGoFiles(paths...); err != nil {
+		return fmt.Errorf("format generated files: %w", err)
+	}
+	if err = genRouter(cmdName); err != nil {
+		return fmt.Errorf("generate router: %w", err)
+	}
+	return nil
+}
+
+// crudData builds the template data of the CRUD endpoints of model on top of
+// its DTOs, which like the service are named after the model struct.
+func crudData(info *genmodel.ModelInfo, projectName, cmdName, controllerName string) (template.CrudData, error) {
+	dtoData, err := dto.TemplateData(info, info.StructName)
+	if err != nil {
+		return template.CrudData{}, err
+	}
+	data := template.CrudData{
+		DTOData:              dtoData,
+		ProjectName:          projectName,
+		CmdName:              cmdName,
+		ControllerStructName: controllerName,
+		TableName:            info.TableName,
+	}
+	var keyArgs, orderBy []string
+	for _, key := range dtoData.KeyFields {
+		keyArgs = append(keyArgs, "req."+key.Name)
+		orderBy = append(orderBy, key.Column)
+	}
+	data.KeyArgs = strings.Join(keyArgs, ", ")
+	data.OrderBy = strings.Join(orderBy, ",")
+	for _, field := range dtoData.RespFields {
+		data.SortColumns = append(data.SortColumns, field.Column)
+	}
+	return data, nil
+}
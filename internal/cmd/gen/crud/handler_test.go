@@ -0,0 +1,160 @@
+package crud
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const userModel = "package user\n\nimport (\n\t\"time\"\n\n\t\"gorm.io/gorm\"\n)\n\n" +
+	"type UserStatus string\n\n" +
+	"type User struct {\n" +
+	"\tId        uint64         `gorm:\"column:id;autoIncrement;primaryKey\" json:\"id\"`\n" +
+	"\tName      string         `gorm:\"column:name;notNull\" json:\"name\"`\n" +
+	"\tStatus    UserStatus     `gorm:\"column:status\" json:\"status\"`\n" +
+	"\tCreatedAt time.Time      `gorm:\"column:created_at;autoCreateTime\" json:\"created_at\"`\n" +
+	"\tDeletedAt gorm.DeletedAt `gorm:\"column:deleted_at\" json:\"deleted_at\"`\n" +
+	"}\n\n" +
+	"func (data *User) TableName() string {\n\treturn \"user\"\n}\n"
+
+const userRoleModel = "package userrole\n\n" +
+	"type UserRole struct {\n" +
+	"\tUserId uint64 `gorm:\"column:user_id;primaryKey\" json:\"user_id\"`\n" +
+	"\tRole   string `gorm:\"column:role;primaryKey\" json:\"role\"`\n" +
+	"}\n\n" +
+	"func (data *UserRole) TableName() string {\n\treturn \"user_role\"\n}\n"
+
+func TestGenCrudScaffoldsControllerDTOAndService(t *testing.T) {
+	root := t.TempDir()
+	config := `{
+  "project_name": "example.com/project",
+  "default_cmd": "api",
+  "cmd_types": {"admin-api": "api", "worker": "worker"}
+}`
+	writeFile(t, filepath.Join(root, "godoconfig.json"), config)
+	writeFile(t, filepath.Join(root, "internal", "common", "models", "user", "model.go"), userModel)
+	writeFile(t, filepath.Join(root, "internal", "common", "models", "userrole", "model.go"), userRoleModel)
+	for _, name := range []string{"admin-api", "worker"} {
+		if err := os.MkdirAll(filepath.Join(root, "cmd", name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("GOD_PROJECT_ROOT", root)
+	previousFormatter, previousRouter := formatGoFiles, genRouter
+	var formatted, routers []string
+	formatGoFiles = func(paths ...string) error {
+		formatted = append(formatted, paths...)
+		return nil
+	}
+	genRouter = func(cmdName string) error {
+		routers = append(routers, cmdName)
+		return nil
+	}
+	t.Cleanup(func() { formatGoFiles, genRouter = previousFormatter, previousRouter })
+
+	if err := genCrud("admin-api", "user", ""); err != nil {
+		t.Fatalf("genCrud() error = %v", err)
+	}
+	base := filepath.Join(root, "internal", "admin-api")
+	expected := map[string][]string{
+		filepath.Join(base, "transport", "http", "api", "controller", "user.go"): {
+			"type UserController struct",
+			"// @http_method GET\n// @middleware\nfunc (ctrl *UserController) List(c *gin.Context, req *dto.UserQueryReq)",
+			"// @http_method PUT\n// @middleware\nfunc (ctrl *UserController) Update(c *gin.Context, req *dto.UserUpdateReq)",
+			"// @http_method DELETE\n// @middleware\nfunc (ctrl *UserController) Delete(c *gin.Context, req *dto.UserKeyReq)",
+			`"example.com/project/internal/admin-api/service"`,
+		},
+		filepath.Join(base, "dto", "user.go"): {
+			`"example.com/project/internal/common/models/user"`,
+			"\t\"time\"\n",
+			"type UserKeyReq struct {\n\tId uint64 `form:\"id\" json:\"id\" binding:\"required\"`\n}",
+			"type UserCreateReq struct {\n\tName string `form:\"name\" json:\"name\" binding:\"required\"`\n" +
+				"\tStatus user.UserStatus `form:\"status\" json:\"status\"`\n}",
+			"type UserUpdateReq struct {\n\tId uint64 `form:\"id\" json:\"id\" binding:\"required\"`\n\tName string",
+			"\tCreatedAt time.Time `json:\"created_at\"`\n}",
+			"func NewUserResp(data *user.User) UserResp {",
+		},
+		filepath.Join(base, "service", "user.go"): {
+			`"example.com/project/internal/common/models/user"`,
+			"repo user.Repository",
+			"return NewUserServiceWith(user.NewRepository())",
+			`var UserSortColumns = []string{"id", "name", "status", "created_at"}`,
+			"Conditions:  req.Conditions(),\n\t\tSort:        sort,\n\t\tSortColumns: UserSortColumns,",
+			"s.repo.Get(req.Id)",
+			"req.ApplyTo(data)",
+		},
+	}
+	for path, snippets := range expected {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := parser.ParseFile(token.NewFileSet(), path, content, 0); err != nil {
+			t.Fatalf("generated file %s does not parse: %v\n%s", path, err, content)
+		}
+		for _, snippet := range snippets {
+			if !strings.Contains(string(content), snippet) {
+				t.Errorf("%s does not contain %q:\n%s", path, snippet, content)
+			}
+		}
+		if strings.Contains(string(content), "DeletedAt") {
+			t.Errorf("%s exposes the soft delete field:\n%s", path, content)
+		}
+	}
+	if len(formatted) != 3 || len(routers) != 1 || routers[0] != "admin-api" {
+		t.Fatalf("formatted = %v, routers = %v", formatted, routers)
+	}
+
+	// Types are named after the model struct, not its package directory.
+	if err := genCrud("admin-api", "userrole", ""); err != nil {
+		t.Fatalf("genCrud(userrole) error = %v", err)
+	}
+	for path, snippets := range map[string][]string{
+		filepath.Join(base, "transport", "http", "api", "controller", "userrole.go"): {
+			"type UserRoleController struct",
+			"service *service.UserRoleService",
+			"func (ctrl *UserRoleController) List(c *gin.Context, req *dto.UserRoleQueryReq)",
+		},
+		filepath.Join(base, "dto", "userrole.go"):     {"type UserRoleKeyReq struct {", "func NewUserRoleResp(data *userrole.UserRole) UserRoleResp {"},
+		filepath.Join(base, "service", "userrole.go"): {"type UserRoleService struct", "var UserRoleSortColumns"},
+	} {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, snippet := range snippets {
+			if !strings.Contains(string(content), snippet) {
+				t.Errorf("%s does not contain %q:\n%s", path, snippet, content)
+			}
+		}
+		if strings.Contains(string(content), "Userrole") {
+			t.Errorf("%s is named after the package:\n%s", path, content)
+		}
+	}
+
+	if err := genCrud("admin-api", "user", ""); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("duplicate crud error = %v", err)
+	}
+	if err := genCrud("admin-api", "missing", ""); err == nil || !strings.Contains(err.Error(), "load model") {
+		t.Fatalf("missing model error = %v", err)
+	}
+	if err := genCrud("admin-api", "../user", "user"); err == nil || !strings.Contains(err.Error(), "invalid model package") {
+		t.Fatalf("invalid model error = %v", err)
+	}
+	if err := genCrud("worker", "user", ""); err == nil || !strings.Contains(err.Error(), "requires \"api\"") {
+		t.Fatalf("worker crud error = %v", err)
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
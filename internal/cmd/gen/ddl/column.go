@@ -0,0 +1,288 @@
+package ddl
+
+import (
+	"fmt"
+	"go/types"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/jiajia556/godo/internal/service"
+)
+
+// valueKind is the kind of value a field stores.
+type valueKind int
+
+const (
+	kindBool valueKind = iota + 1
+	kindInt
+	kindUint
+	kindFloat
+	kindString
+	kindBytes
+	kindTime
+	kindUnixTime
+	kindDecimal
+	kindJSON
+)
+
+// valueType is what a field's Go type says about its column.
+type valueType struct {
+	kind valueKind
+	bits int
+	// enum lists the allowed values of a string type with constants; set
+	// marks a slice of such a type, stored as a SET column.
+	enum []string
+	set  bool
+}
+
+// knownTypes maps the qualified names of the non-builtin types GORM stores in
+// a single column.
+var knownTypes = map[string]valueType{
+	"time.Time":                             {kind: kindTime},
+	"gorm.io/gorm.DeletedAt":                {kind: kindTime},
+	"gorm.io/plugin/soft_delete.DeletedAt":  {kind: kindUnixTime},
+	"github.com/shopspring/decimal.Decimal": {kind: kindDecimal},
+	"gorm.io/datatypes.JSON":                {kind: kindJSON},
+	"gorm.io/datatypes.Date":                {kind: kindTime},
+	"database/sql.NullString":               {kind: kindString},
+	"database/sql.NullInt64":                {kind: kindInt, bits: 64},
+	"database/sql.NullInt32":                {kind: kindInt, bits: 32},
+	"database/sql.NullInt16":                {kind: kindInt, bits: 16},
+	"database/sql.NullByte":                 {kind: kindUint, bits: 8},
+	"database/sql.NullBool":                 {kind: kindBool},
+	"database/sql.NullFloat64":              {kind: kindFloat, bits: 64},
+	"database/sql.NullTime":                 {kind: kindTime},
+}
+
+// knownTypesByExpr maps the same types by the way they are written in source,
+// such as "gorm.DeletedAt", for fields whose type did not resolve.
+var knownTypesByExpr = func() map[string]valueType {
+	byExpr := make(map[string]valueType, len(knownTypes))
+	for qualified, value := range knownTypes {
+		dot := strings.LastIndex(qualified, ".")
+		byExpr[path.Base(qualified[:dot])+qualified[dot:]] = value
+	}
+	return byExpr
+}()
+
+func qualifiedName(named *types.Named) string {
+	if named.Obj().Pkg() == nil {
+		return named.Obj().Name()
+	}
+	return named.Obj().Pkg().Path() + "." + named.Obj().Name()
+}
+
+func isKnownType(typ types.Type) bool {
+	named, ok := derefType(typ).(*types.Named)
+	if !ok {
+		return false
+	}
+	_, ok = knownTypes[qualifiedName(named)]
+	return ok
+}
+
+// fieldValueType classifies the Go type of field.
+func fieldValueType(field modelField) (valueType, error) {
+	if field.typ == nil {
+		if value, ok := knownTypesByExpr[strings.TrimPrefix(field.expr, "*")]; ok {
+			return value, nil
+		}
+		if field.expr == "" {
+			return valueType{}, fmt.Errorf("field %s: unresolved type; add a gorm type tag", field.name)
+		}
+		return valueType{}, fmt.Errorf("field %s: cannot map unresolved type %s; add a gorm type tag", field.name, field.expr)
+	}
+	value, ok := classifyType(field.typ, field.consts)
+	if !ok {
+		return valueType{}, fmt.Errorf("field %s: cannot map type %s; add a gorm type tag", field.name, field.typ)
+	}
+	return value, nil
+}
+
+func classifyType(typ types.Type, consts map[*types.TypeName][]string) (valueType, bool) {
+	typ = derefType(typ)
+	if named, ok := typ.(*types.Named); ok {
+		if value, ok := knownTypes[qualifiedName(named)]; ok {
+			return value, true
+		}
+		if values := consts[named.Obj()]; len(values) > 0 {
+			if basic, ok := named.Underlying().(*types.Basic); ok && basic.Info()&types.IsString != 0 {
+				return valueType{kind: kindString, enum: values}, true
+			}
+		}
+	}
+
+	switch t := typ.Underlying().(type) {
+	case *types.Slice:
+		if basic, ok := t.Elem().Underlying().(*types.Basic); ok && basic.Kind() == types.Byte {
+			return valueType{kind: kindBytes}, true
+		}
+		if elem, ok := classifyType(t.Elem(), consts); ok && len(elem.enum) > 0 {
+			elem.set = true
+			return elem, true
+		}
+	case *types.Basic:
+		switch {
+		case t.Kind() == types.Bool:
+			return valueType{kind: kindBool}, true
+		case t.Info()&types.IsUnsigned != 0:
+			return valueType{kind: kindUint, bits: intBits(t.Kind())}, true
+		case t.Info()&types.IsInteger != 0:
+			return valueType{kind: kindInt, bits: intBits(t.Kind())}, true
+		case t.Kind() == types.Float32:
+			return valueType{kind: kindFloat, bits: 32}, true
+		case t.Kind() == types.Float64:
+			return valueType{kind: kindFloat, bits: 64}, true
+		case t.Info()&types.IsString != 0:
+			return valueType{kind: kindString}, true
+		}
+	}
+	return valueType{}, false
+}
+
+func intBits(kind types.BasicKind) int {
+	switch kind {
+	case types.Int8, types.Uint8:
+		return 8
+	case types.Int16, types.Uint16:
+		return 16
+	case types.Int32, types.Uint32:
+		return 32
+	}
+	return 64
+}
+
+// columnType returns the SQL type of a column in dialect, following the
+// defaults of the GORM MySQL and SQLite drivers. A gorm type tag is used as is.
+func columnType(value valueType, tag gormTag, indexed bool, dialect string) string {
+	if sqlType := tag.value("TYPE"); sqlType != "" {
+		return sqlType
+	}
+	size, _ := strconv.Atoi(tag.value("SIZE"))
+	precision, hasPrecision := tagInt(tag, "PRECISION")
+	scale, _ := tagInt(tag, "SCALE")
+
+	if dialect == service.DriverSqlite {
+		switch value.kind {
+		case kindBool:
+			return "numeric"
+		case kindInt, kindUint, kindUnixTime:
+			return "integer"
+		case kindFloat:
+			return "real"
+		case kindBytes:
+			return "blob"
+		case kindTime:
+			return "datetime"
+		case kindDecimal:
+			return "numeric"
+		}
+		return "text"
+	}
+
+	switch value.kind {
+	case kindBool:
+		return "boolean"
+	case kindInt, kindUint:
+		bits := value.bits
+		if size > 0 {
+			bits = size
+		}
+		sqlType := "bigint"
+		switch {
+		case bits <= 8:
+			sqlType = "tinyint"
+		case bits <= 16:
+			sqlType = "smallint"
+		case bits <= 24:
+			sqlType = "mediumint"
+		case bits <= 32:
+			sqlType = "int"
+		}
+		if value.kind == kindUint || tag.has("UNSIGNED") {
+			sqlType += " unsigned"
+		}
+		return sqlType
+	case kindUnixTime:
+		return "bigint unsigned"
+	case kindFloat:
+		if hasPrecision && precision > 0 {
+			return fmt.Sprintf("decimal(%d,%d)", precision, scale)
+		}
+		if value.bits == 32 || size > 0 && size <= 32 {
+			return "float"
+		}
+		return "double"
+	case kindDecimal:
+		if hasPrecision && precision > 0 {
+			return fmt.Sprintf("decimal(%d,%d)", precision, scale)
+		}
+		return "decimal"
+	case kindTime:
+		if !hasPrecision {
+			precision = 3
+		}
+		if precision > 0 {
+			return fmt.Sprintf("datetime(%d)", precision)
+		}
+		return "datetime"
+	case kindJSON:
+		return "json"
+	case kindBytes:
+		if size > 0 && size < 65536 {
+			return fmt.Sprintf("varbinary(%d)", size)
+		}
+		return "longblob"
+	}
+
+	if len(value.enum) > 0 {
+		quoted := make([]string, 0, len(value.enum))
+		for _, item := range value.enum {
+			quoted = append(quoted, quoteString(item))
+		}
+		if value.set {
+			return "set(" + strings.Join(quoted, ",") + ")"
+		}
+		return "enum(" + strings.Join(quoted, ",") + ")"
+	}
+	if size == 0 && (indexed || tag.has("DEFAULT")) {
+		size = 191
+	}
+	switch {
+	case size >= 65536 && size <= 1<<24:
+		return "mediumtext"
+	case size > 1<<24 || size <= 0:
+		return "longtext"
+	}
+	return fmt.Sprintf("varchar(%d)", size)
+}
+
+func tagInt(tag gormTag, key string) (int, bool) {
+	if !tag.has(key) {
+		return 0, false
+	}
+	n, err := strconv.Atoi(tag.value(key))
+	return n, err == nil
+}
+
+// defaultValue renders a gorm default tag as a SQL literal. String and time
+// values are quoted unless they already are or name an expression, such as
+// CURRENT_TIMESTAMP.
+func defaultValue(value valueType, def string) string {
+	switch value.kind {
+	case kindString, kindTime, kindJSON:
+	default:
+		return def
+	}
+	upper := strings.ToUpper(def)
+	if strings.HasPrefix(def, "'") || strings.HasPrefix(def, "\"") || strings.Contains(def, "(") ||
+		upper == "NULL" || strings.HasPrefix(upper, "CURRENT_TIMESTAMP") {
+		return def
+	}
+	return quoteString(def)
+}
+
+func quoteString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
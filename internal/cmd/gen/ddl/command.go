@@ -0,0 +1,30 @@
+package ddl
+
+import (
+	"github.com/jiajia556/godo/internal/service"
+	"github.com/spf13/cobra"
+)
+
+var ddlCmd = &cobra.Command{
+	Use:     "ddl",
+	Short:   "Generate CREATE TABLE statements from model structs",
+	Long:    "Loads the model packages, reads struct fields and gorm tags, and writes a CREATE TABLE statement per model into a schema file.",
+	Example: "  godo gen ddl\n  godo gen ddl --models ./internal/common/models/... --out schema.sql\n  godo gen ddl --dialect sqlite --out schema.sqlite.sql",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		models, _ := cmd.Flags().GetStringSlice("models")
+		dialect, _ := cmd.Flags().GetString("dialect")
+		out, _ := cmd.Flags().GetString("out")
+		return genDDL(models, dialect, out)
+	},
+}
+
+func GetCommand() *cobra.Command {
+	return ddlCmd
+}
+
+func init() {
+	ddlCmd.Flags().StringSlice("models", []string{"./internal/common/models/..."}, "Model package directories relative to the project root; a trailing /... includes subdirectories")
+	ddlCmd.Flags().String("dialect", service.DriverMysql, "SQL dialect: mysql or sqlite")
+	ddlCmd.Flags().String("out", "schema.sql", "Schema file to write, relative to the project root")
+}
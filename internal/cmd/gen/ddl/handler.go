@@ -0,0 +1,99 @@
+package ddl
+
+import (
+	"fmt"
+	"go/token"
+	"sort"
+	"strings"
+
+	"github.com/jiajia556/godo/internal/service"
+	"github.com/jiajia556/godo/internal/utils"
+)
+
+// genDDL loads the model packages matched by patterns and writes a CREATE
+// TABLE statement per model to out, both relative to the project root.
+func genDDL(patterns []string, dialect, out string) error {
+	dialect, err := normalizeDialect(dialect)
+	if err != nil {
+		return err
+	}
+	root, err := service.GetProjectRoot()
+	if err != nil {
+		return fmt.Errorf("get project root: %w", err)
+	}
+	tables, err := generateDDL(root, patterns, dialect)
+	if err != nil {
+		return err
+	}
+	if len(tables) == 0 {
+		return fmt.Errorf("no models found in %s", strings.Join(patterns, ", "))
+	}
+
+	outPath, err := service.GetAbsPath(out)
+	if err != nil {
+		return fmt.Errorf("resolve output file: %w", err)
+	}
+	content := fmt.Sprintf("-- Generated by godo gen ddl from %s for %s.\n\n%s\n", strings.Join(patterns, ", "), dialect, strings.Join(tables, "\n\n"))
+	if err := utils.WriteFile(outPath, content); err != nil {
+		return fmt.Errorf("write %s: %w", outPath, err)
+	}
+	utils.OutputInfof("wrote %d tables to %s", len(tables), outPath)
+	return nil
+}
+
+// generateDDL returns the CREATE statements of the models in the packages
+// matched by patterns, ordered by table name.
+func generateDDL(root string, patterns []string, dialect string) ([]string, error) {
+	dirs, err := modelDirs(root, patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	owners := make(map[string]string)
+	statements := make(map[string]string)
+	var tables []string
+	for _, dir := range dirs {
+		pkg, err := loadPackage(fset, dir)
+		if err != nil {
+			return nil, err
+		}
+		if pkg == nil {
+			continue
+		}
+		models, err := pkg.models()
+		if err != nil {
+			return nil, err
+		}
+		for _, model := range models {
+			owner := model.pkg + "." + model.name
+			if previous, ok := owners[model.table]; ok {
+				return nil, fmt.Errorf("table %s is mapped by both %s and %s", model.table, previous, owner)
+			}
+			owners[model.table] = owner
+			table, err := buildTable(model, dialect)
+			if err != nil {
+				return nil, fmt.Errorf("model %s: %w", owner, err)
+			}
+			tables = append(tables, model.table)
+			statements[model.table] = renderTable(table, dialect)
+		}
+	}
+	sort.Strings(tables)
+	ordered := make([]string, 0, len(tables))
+	for _, table := range tables {
+		ordered = append(ordered, statements[table])
+	}
+	return ordered, nil
+}
+
+func normalizeDialect(dialect string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(dialect)) {
+	case "", service.DriverMysql:
+		return service.DriverMysql, nil
+	case service.DriverSqlite, "sqlite3":
+		return service.DriverSqlite, nil
+	default:
+		return "", fmt.Errorf("unsupported dialect %q; expected mysql or sqlite", dialect)
+	}
+}
@@ -0,0 +1,192 @@
+package ddl
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jiajia556/godo/internal/service"
+)
+
+const usersModel = `package users
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type UsersStatus string
+
+const (
+	UsersStatusActive   UsersStatus = "active"
+	UsersStatusDisabled UsersStatus = "disabled"
+)
+
+type Users struct {
+	Id        uint64         ` + "`" + `gorm:"column:id;notNull;primaryKey;autoIncrement;unsigned" json:"id"` + "`" + `
+	Email     string         ` + "`" + `gorm:"column:email;size:128;notNull;uniqueIndex:uk_email" json:"email"` + "`" + `
+	Status    UsersStatus    ` + "`" + `gorm:"column:status;notNull;default:active" json:"status"` + "`" + `
+	Age       *int32         ` + "`" + `gorm:"column:age;comment:age in years" json:"age"` + "`" + `
+	CreatedAt time.Time      ` + "`" + `gorm:"column:created_at;notNull;autoCreateTime" json:"created_at"` + "`" + `
+	DeletedAt gorm.DeletedAt ` + "`" + `gorm:"column:deleted_at;index" json:"deleted_at"` + "`" + `
+	Orders    []Orders       ` + "`" + `gorm:"foreignKey:UserId" json:"orders,omitempty"` + "`" + `
+	internal  string
+}
+
+type Orders struct{}
+
+func (data *Users) TableName() string {
+	return "users"
+}
+`
+
+const articleModel = `package article
+
+import "gorm.io/gorm"
+
+type Article struct {
+	gorm.Model
+	Title    string ` + "`" + `gorm:"size:200;index:idx_title_author,priority:2"` + "`" + `
+	AuthorID uint   ` + "`" + `gorm:"index:idx_title_author,priority:1"` + "`" + `
+	Body     string
+	Score    float64 ` + "`" + `gorm:"precision:10;scale:2"` + "`" + `
+	Ignored  string  ` + "`" + `gorm:"-"` + "`" + `
+}
+`
+
+const baseModels = `package models
+
+type BaseRecord[T any] struct {
+	Model T
+}
+`
+
+func writeModelTree(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		"internal/common/models/baserecord.go":    baseModels,
+		"internal/common/models/users/model.go":   usersModel,
+		"internal/common/models/article/model.go": articleModel,
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestGenerateDDLForMySQL(t *testing.T) {
+	root := writeModelTree(t)
+	statements, err := generateDDL(root, []string{"./internal/common/models/..."}, service.DriverMysql)
+	if err != nil {
+		t.Fatalf("generateDDL() error = %v", err)
+	}
+	if len(statements) != 2 {
+		t.Fatalf("statements = %q", statements)
+	}
+	article, users := statements[0], statements[1]
+	for _, want := range []string{
+		"CREATE TABLE `articles` (",
+		"`id` bigint unsigned NOT NULL AUTO_INCREMENT",
+		"`created_at` datetime(3)",
+		"`deleted_at` datetime(3)",
+		"`title` varchar(200)",
+		"`author_id` bigint unsigned",
+		"`body` longtext",
+		"`score` decimal(10,2)",
+		"PRIMARY KEY (`id`)",
+		"KEY `idx_articles_deleted_at` (`deleted_at`)",
+		"KEY `idx_title_author` (`author_id`,`title`)",
+	} {
+		if !strings.Contains(article, want) {
+			t.Errorf("articles DDL does not contain %q:\n%s", want, article)
+		}
+	}
+	if strings.Contains(article, "ignored") {
+		t.Errorf("ignored field became a column:\n%s", article)
+	}
+	for _, want := range []string{
+		"CREATE TABLE `users` (",
+		"`id` bigint unsigned NOT NULL AUTO_INCREMENT",
+		"`email` varchar(128) NOT NULL",
+		"`status` enum('active','disabled') NOT NULL DEFAULT 'active'",
+		"`age` int COMMENT 'age in years'",
+		"`created_at` datetime(3) NOT NULL",
+		"`deleted_at` datetime(3)",
+		"UNIQUE KEY `uk_email` (`email`)",
+		"KEY `idx_users_deleted_at` (`deleted_at`)",
+	} {
+		if !strings.Contains(users, want) {
+			t.Errorf("users DDL does not contain %q:\n%s", want, users)
+		}
+	}
+	for _, unwanted := range []string{"orders", "internal"} {
+		if strings.Contains(users, unwanted) {
+			t.Errorf("users DDL contains %q:\n%s", unwanted, users)
+		}
+	}
+}
+
+func TestGenerateDDLForSQLite(t *testing.T) {
+	root := writeModelTree(t)
+	statements, err := generateDDL(root, []string{"internal/common/models/users"}, service.DriverSqlite)
+	if err != nil {
+		t.Fatalf("generateDDL() error = %v", err)
+	}
+	if len(statements) != 1 {
+		t.Fatalf("statements = %q", statements)
+	}
+	for _, want := range []string{
+		`CREATE TABLE "users" (`,
+		`"id" integer PRIMARY KEY AUTOINCREMENT`,
+		`"status" text NOT NULL DEFAULT 'active'`,
+		`CREATE UNIQUE INDEX "uk_email" ON "users" ("email");`,
+		`CREATE INDEX "idx_users_deleted_at" ON "users" ("deleted_at");`,
+	} {
+		if !strings.Contains(statements[0], want) {
+			t.Errorf("DDL does not contain %q:\n%s", want, statements[0])
+		}
+	}
+}
+
+func TestGenerateDDLReportsUnmappableFields(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "models", "events")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	src := "package events\n\nimport \"example.com/missing/geo\"\n\ntype Events struct {\n\tID    uint64\n\tPlace geo.Point `gorm:\"column:place\"`\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, "model.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := generateDDL(root, []string{"models/..."}, service.DriverMysql)
+	if err == nil || !strings.Contains(err.Error(), "geo.Point") || !strings.Contains(err.Error(), "type tag") {
+		t.Fatalf("generateDDL() error = %v", err)
+	}
+
+	src = strings.Replace(src, "`gorm:\"column:place\"`", "`gorm:\"column:place;type:point\"`", 1)
+	if err := os.WriteFile(filepath.Join(dir, "model.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	statements, err := generateDDL(root, []string{"models/..."}, service.DriverMysql)
+	if err != nil || len(statements) != 1 || !strings.Contains(statements[0], "`place` point") {
+		t.Fatalf("generateDDL() = %q, %v", statements, err)
+	}
+}
+
+func TestNormalizeDialect(t *testing.T) {
+	if dialect, err := normalizeDialect("SQLite3"); err != nil || dialect != service.DriverSqlite {
+		t.Fatalf("normalizeDialect() = %q, %v", dialect, err)
+	}
+	if _, err := normalizeDialect("postgres"); err == nil {
+		t.Fatal("normalizeDialect() accepted postgres")
+	}
+}
@@ -0,0 +1,412 @@
+package ddl
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// modelStruct is a Go struct mapped to a table.
+type modelStruct struct {
+	name   string
+	pkg    string
+	table  string
+	fields []modelField
+}
+
+// modelField is an exported struct field mapped to a column. typ is nil when
+// the field's type could not be resolved, for example because a dependency of
+// the model package is not downloaded; expr then holds the type as written.
+type modelField struct {
+	name string
+	typ  types.Type
+	expr string
+	tag  gormTag
+	// consts maps a named type declared in the model package to the values
+	// of its constants, so string enum types become ENUM columns.
+	consts map[*types.TypeName][]string
+}
+
+// loadedPackage is a type-checked model package.
+type loadedPackage struct {
+	dir   string
+	files []*ast.File
+	pkg   *types.Package
+	info  *types.Info
+	// exprs holds the type expression of every struct field by position, for
+	// fields whose type did not resolve.
+	exprs map[token.Pos]ast.Expr
+}
+
+// modelDirs expands the --models patterns into package directories. A pattern
+// ending in "/..." includes every directory below it.
+func modelDirs(root string, patterns []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var dirs []string
+	add := func(dir string) {
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	for _, pattern := range patterns {
+		recursive := strings.HasSuffix(pattern, "/...") || pattern == "..."
+		dir := strings.TrimSuffix(strings.TrimSuffix(pattern, "..."), "/")
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(root, dir)
+		}
+		info, err := os.Stat(dir)
+		if err != nil {
+			return nil, fmt.Errorf("inspect model directory %s: %w", dir, err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("model path is not a directory: %s", dir)
+		}
+		if !recursive {
+			add(dir)
+			continue
+		}
+		err = filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !entry.IsDir() {
+				return nil
+			}
+			name := entry.Name()
+			if path != dir && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata" || name == "vendor") {
+				return filepath.SkipDir
+			}
+			add(path)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("walk model directory %s: %w", dir, err)
+		}
+	}
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// loadPackage parses and type-checks the non-test Go files in dir. Type errors
+// are tolerated: the model packages of a project usually import packages that
+// are only partly resolvable, and unresolved field types fall back to their
+// written form. It returns nil when dir holds no Go files.
+func loadPackage(fset *token.FileSet, dir string) (*loadedPackage, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read model directory %s: %w", dir, err)
+	}
+	var files []*ast.File
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, fmt.Errorf("parse model file: %w", err)
+		}
+		files = append(files, file)
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	info := &types.Info{
+		Types: make(map[ast.Expr]types.TypeAndValue),
+		Defs:  make(map[*ast.Ident]types.Object),
+	}
+	config := types.Config{
+		Importer: importer.ForCompiler(fset, "gc", nil),
+		Error:    func(error) {},
+	}
+	pkg, _ := config.Check(files[0].Name.Name, fset, files, info)
+
+	loaded := &loadedPackage{dir: dir, files: files, pkg: pkg, info: info, exprs: make(map[token.Pos]ast.Expr)}
+	for _, file := range files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			structType, ok := node.(*ast.StructType)
+			if !ok {
+				return true
+			}
+			for _, field := range structType.Fields.List {
+				if len(field.Names) == 0 {
+					loaded.exprs[embeddedPos(field.Type)] = field.Type
+				}
+				for _, name := range field.Names {
+					loaded.exprs[name.Pos()] = field.Type
+				}
+			}
+			return true
+		})
+	}
+	return loaded, nil
+}
+
+// embeddedPos returns the position go/types reports for an embedded field: the
+// position of the type name.
+func embeddedPos(expr ast.Expr) token.Pos {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return embeddedPos(e.X)
+	case *ast.SelectorExpr:
+		return e.Sel.Pos()
+	case *ast.IndexExpr:
+		return embeddedPos(e.X)
+	case *ast.IndexListExpr:
+		return embeddedPos(e.X)
+	}
+	return expr.Pos()
+}
+
+// models returns the structs of the package that map to tables: those with a
+// TableName method or at least one gorm tag.
+func (p *loadedPackage) models() ([]modelStruct, error) {
+	tableNames := p.tableNameMethods()
+	consts := p.constValues()
+
+	var models []modelStruct
+	scope := p.pkg.Scope()
+	for _, name := range scope.Names() {
+		typeName, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || typeName.IsAlias() || !typeName.Exported() {
+			continue
+		}
+		named, ok := typeName.Type().(*types.Named)
+		if !ok || named.TypeParams().Len() > 0 {
+			continue
+		}
+		structType, ok := named.Underlying().(*types.Struct)
+		if !ok {
+			continue
+		}
+		table, hasTableName := tableNames[name]
+		if !hasTableName && !hasGormTags(structType) {
+			continue
+		}
+		fields, err := p.structFields(structType, "", consts)
+		if err != nil {
+			return nil, fmt.Errorf("model %s.%s: %w", p.pkg.Name(), name, err)
+		}
+		if table == "" {
+			table = defaultTableName(name)
+		}
+		models = append(models, modelStruct{name: name, pkg: p.pkg.Name(), table: table, fields: fields})
+	}
+	return models, nil
+}
+
+// structFields flattens the columns of structType: embedded structs and
+// fields tagged embedded contribute their own fields; associations and
+// ignored fields are skipped.
+func (p *loadedPackage) structFields(structType *types.Struct, prefix string, consts map[*types.TypeName][]string) ([]modelField, error) {
+	var fields []modelField
+	for i := 0; i < structType.NumFields(); i++ {
+		v := structType.Field(i)
+		if !v.Exported() {
+			continue
+		}
+		tag := parseGormTag(structType.Tag(i))
+		if tag.ignored() {
+			continue
+		}
+
+		typ := v.Type()
+		field := modelField{name: v.Name(), typ: typ, tag: tag, consts: consts}
+		if !isValidType(typ) {
+			field.typ = nil
+			if expr, ok := p.exprs[v.Pos()]; ok {
+				field.expr = types.ExprString(expr)
+			}
+		}
+		if prefix != "" && !tag.has("COLUMN") {
+			field.tag.set("COLUMN", prefix+columnName(v.Name()))
+		}
+
+		if v.Embedded() || tag.has("EMBEDDED") {
+			embeddedPrefix := prefix + tag.value("EMBEDDEDPREFIX")
+			if field.typ != nil {
+				if embedded, ok := derefType(field.typ).Underlying().(*types.Struct); ok && !isKnownType(field.typ) {
+					nested, err := p.structFields(embedded, embeddedPrefix, consts)
+					if err != nil {
+						return nil, err
+					}
+					fields = append(fields, nested...)
+					continue
+				}
+			} else if strings.TrimPrefix(field.expr, "*") == "gorm.Model" {
+				fields = append(fields, gormModelFields(embeddedPrefix)...)
+				continue
+			}
+		}
+		if isAssociation(field) {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// gormModelFields returns the fields of gorm.Model for packages where gorm
+// could not be loaded.
+func gormModelFields(prefix string) []modelField {
+	column := func(name string) gormTag {
+		var tag gormTag
+		if prefix != "" {
+			tag.set("COLUMN", prefix+columnName(name))
+		}
+		return tag
+	}
+	primaryKey := column("ID")
+	primaryKey.set("PRIMARYKEY", "")
+	deletedAt := column("DeletedAt")
+	deletedAt.set("INDEX", "")
+	return []modelField{
+		{name: "ID", typ: types.Typ[types.Uint], tag: primaryKey},
+		{name: "CreatedAt", expr: "time.Time", tag: column("CreatedAt")},
+		{name: "UpdatedAt", expr: "time.Time", tag: column("UpdatedAt")},
+		{name: "DeletedAt", expr: "gorm.DeletedAt", tag: deletedAt},
+	}
+}
+
+// isAssociation reports whether field refers to other models rather than
+// holding a column value.
+func isAssociation(field modelField) bool {
+	for _, key := range []string{"FOREIGNKEY", "REFERENCES", "MANY2MANY", "POLYMORPHIC", "JOINFOREIGNKEY"} {
+		if field.tag.has(key) {
+			return true
+		}
+	}
+	if field.typ == nil || field.tag.has("TYPE") || isKnownType(field.typ) {
+		return false
+	}
+	typ := derefType(field.typ)
+	if slice, ok := typ.Underlying().(*types.Slice); ok {
+		typ = derefType(slice.Elem())
+	}
+	_, isStruct := typ.Underlying().(*types.Struct)
+	return isStruct && !hasValueMethod(field.typ)
+}
+
+// tableNameMethods returns the table names returned by the TableName methods
+// of the package, keyed by receiver type. The value is empty when the method
+// does not return a constant.
+func (p *loadedPackage) tableNameMethods() map[string]string {
+	names := make(map[string]string)
+	for _, file := range p.files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || fn.Name.Name != "TableName" || fn.Body == nil {
+				continue
+			}
+			receiver := receiverTypeName(fn.Recv)
+			if receiver == "" {
+				continue
+			}
+			names[receiver] = ""
+			if len(fn.Body.List) != 1 {
+				continue
+			}
+			ret, ok := fn.Body.List[0].(*ast.ReturnStmt)
+			if !ok || len(ret.Results) != 1 {
+				continue
+			}
+			if value := p.info.Types[ret.Results[0]].Value; value != nil && value.Kind() == constant.String {
+				names[receiver] = constant.StringVal(value)
+			}
+		}
+	}
+	return names
+}
+
+// constValues returns the string constants of the package grouped by their
+// named type, in declaration order.
+func (p *loadedPackage) constValues() map[*types.TypeName][]string {
+	type constDecl struct {
+		pos   token.Pos
+		value string
+	}
+	byType := make(map[*types.TypeName][]constDecl)
+	for _, object := range p.info.Defs {
+		c, ok := object.(*types.Const)
+		if !ok || c.Val().Kind() != constant.String {
+			continue
+		}
+		named, ok := c.Type().(*types.Named)
+		if !ok || named.Obj().Pkg() != p.pkg {
+			continue
+		}
+		byType[named.Obj()] = append(byType[named.Obj()], constDecl{c.Pos(), constant.StringVal(c.Val())})
+	}
+	values := make(map[*types.TypeName][]string, len(byType))
+	for typeName, decls := range byType {
+		sort.Slice(decls, func(i, j int) bool { return decls[i].pos < decls[j].pos })
+		for _, decl := range decls {
+			values[typeName] = append(values[typeName], decl.value)
+		}
+	}
+	return values
+}
+
+func receiverTypeName(recv *ast.FieldList) string {
+	if recv == nil || len(recv.List) == 0 {
+		return ""
+	}
+	expr := recv.List[0].Type
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+func hasGormTags(structType *types.Struct) bool {
+	for i := 0; i < structType.NumFields(); i++ {
+		if len(parseGormTag(structType.Tag(i))) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func isValidType(typ types.Type) bool {
+	if typ == nil {
+		return false
+	}
+	if basic, ok := derefType(typ).(*types.Basic); ok && basic.Kind() == types.Invalid {
+		return false
+	}
+	if slice, ok := typ.(*types.Slice); ok {
+		return isValidType(slice.Elem())
+	}
+	return true
+}
+
+func derefType(typ types.Type) types.Type {
+	if pointer, ok := typ.(*types.Pointer); ok {
+		return pointer.Elem()
+	}
+	return typ
+}
+
+// hasValueMethod reports whether typ implements driver.Valuer by name, which
+// makes a struct type a column rather than an association.
+func hasValueMethod(typ types.Type) bool {
+	for _, t := range []types.Type{typ, types.NewPointer(derefType(typ))} {
+		if types.NewMethodSet(t).Lookup(nil, "Value") != nil {
+			return true
+		}
+	}
+	return false
+}
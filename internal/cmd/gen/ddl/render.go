@@ -0,0 +1,279 @@
+package ddl
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	genmodel "github.com/jiajia556/godo/internal/cmd/gen/model"
+	"github.com/jiajia556/godo/internal/service"
+)
+
+// tableDef is the table a model maps to.
+type tableDef struct {
+	name       string
+	columns    []columnDef
+	primaryKey []string
+	indexes    []genmodel.IndexSchema
+}
+
+type columnDef struct {
+	name          string
+	sqlType       string
+	notNull       bool
+	autoIncrement bool
+	unique        bool
+	defaultValue  string
+	hasDefault    bool
+	comment       string
+}
+
+// indexColumn is a column of an index tag with its priority.
+type indexColumn struct {
+	genmodel.IndexColumn
+	priority int
+	order    int
+}
+
+// buildTable maps the fields of a model to columns and indexes the way GORM's
+// migrator does for dialect.
+func buildTable(model modelStruct, dialect string) (*tableDef, error) {
+	table := &tableDef{name: model.table}
+	values := make([]valueType, len(model.fields))
+	columns := make([]string, len(model.fields))
+	seen := make(map[string]bool)
+	for i, field := range model.fields {
+		value, err := fieldValueType(field)
+		if err != nil && !field.tag.has("TYPE") {
+			return nil, err
+		}
+		values[i] = value
+		columns[i] = field.tag.value("COLUMN")
+		if columns[i] == "" {
+			columns[i] = columnName(field.name)
+		}
+		if seen[columns[i]] {
+			return nil, fmt.Errorf("column %s is mapped by more than one field", columns[i])
+		}
+		seen[columns[i]] = true
+		if field.tag.enabled("PRIMARYKEY") {
+			table.primaryKey = append(table.primaryKey, columns[i])
+		}
+	}
+	if len(table.primaryKey) == 0 && seen["id"] {
+		table.primaryKey = []string{"id"}
+	}
+
+	indexes, indexed, err := collectIndexes(model, columns)
+	if err != nil {
+		return nil, err
+	}
+	table.indexes = indexes
+
+	for i, field := range model.fields {
+		isPrimaryKey := containsString(table.primaryKey, columns[i])
+		column := columnDef{
+			name:    columns[i],
+			notNull: field.tag.enabled("NOTNULL") || isPrimaryKey,
+			unique:  field.tag.enabled("UNIQUE"),
+			comment: field.tag.value("COMMENT"),
+		}
+		column.sqlType = columnType(values[i], field.tag, indexed[columns[i]] || isPrimaryKey || column.unique, dialect)
+		integer := values[i].kind == kindInt || values[i].kind == kindUint
+		if field.tag.has("AUTOINCREMENT") {
+			column.autoIncrement = field.tag.enabled("AUTOINCREMENT")
+		} else {
+			column.autoIncrement = isPrimaryKey && len(table.primaryKey) == 1 && integer && !field.tag.has("DEFAULT")
+		}
+		if field.tag.has("DEFAULT") && field.tag.value("DEFAULT") != "(-)" {
+			def := field.tag.value("DEFAULT")
+			if def != "" || values[i].kind == kindString {
+				column.defaultValue, column.hasDefault = defaultValue(values[i], def), true
+			}
+		}
+		table.columns = append(table.columns, column)
+	}
+	return table, nil
+}
+
+// collectIndexes groups the index and uniqueIndex tags of the model into
+// indexes, ordering composite index columns by priority. It also reports the
+// indexed columns.
+func collectIndexes(model modelStruct, columns []string) ([]genmodel.IndexSchema, map[string]bool, error) {
+	var order []string
+	kinds := make(map[string]string)
+	members := make(map[string][]indexColumn)
+	indexed := make(map[string]bool)
+	for i, field := range model.fields {
+		for _, key := range []string{"INDEX", "UNIQUEINDEX"} {
+			for _, value := range field.tag.values(key) {
+				settings := strings.Split(value, ",")
+				name := strings.TrimSpace(settings[0])
+				if name == "" {
+					name = "idx_" + model.table + "_" + columns[i]
+				}
+				kind := genmodel.IndexPlain
+				if key == "UNIQUEINDEX" {
+					kind = genmodel.IndexUnique
+				}
+				member := indexColumn{IndexColumn: genmodel.IndexColumn{Name: columns[i]}, priority: 10, order: i}
+				for _, setting := range settings[1:] {
+					option, optionValue, _ := strings.Cut(strings.TrimSpace(setting), ":")
+					var err error
+					switch strings.ToUpper(option) {
+					case "UNIQUE":
+						kind = genmodel.IndexUnique
+					case "CLASS":
+						switch class := strings.ToUpper(optionValue); class {
+						case genmodel.IndexUnique, genmodel.IndexFulltext, genmodel.IndexSpatial:
+							kind = class
+						}
+					case "PRIORITY":
+						member.priority, err = strconv.Atoi(optionValue)
+					case "LENGTH":
+						member.Length, err = strconv.Atoi(optionValue)
+					}
+					if err != nil {
+						return nil, nil, fmt.Errorf("field %s: invalid index setting %q", field.name, setting)
+					}
+				}
+				if _, ok := members[name]; !ok {
+					order = append(order, name)
+					kinds[name] = kind
+				} else if kind != genmodel.IndexPlain {
+					kinds[name] = kind
+				}
+				members[name] = append(members[name], member)
+				indexed[columns[i]] = true
+			}
+		}
+	}
+
+	indexes := make([]genmodel.IndexSchema, 0, len(order))
+	for _, name := range order {
+		cols := members[name]
+		sort.SliceStable(cols, func(a, b int) bool {
+			if cols[a].priority != cols[b].priority {
+				return cols[a].priority < cols[b].priority
+			}
+			return cols[a].order < cols[b].order
+		})
+		index := genmodel.IndexSchema{Name: name, Kind: kinds[name]}
+		for _, col := range cols {
+			index.Columns = append(index.Columns, col.IndexColumn)
+		}
+		indexes = append(indexes, index)
+	}
+	return indexes, indexed, nil
+}
+
+// renderMySQL renders the table as a MySQL CREATE TABLE statement.
+func renderMySQL(table *tableDef) string {
+	schema := &genmodel.TableSchema{Name: table.name}
+	for _, column := range table.columns {
+		def := column.sqlType
+		if column.notNull {
+			def += " NOT NULL"
+		}
+		if column.autoIncrement {
+			def += " AUTO_INCREMENT"
+		}
+		if column.unique {
+			def += " UNIQUE"
+		}
+		if column.hasDefault {
+			def += " DEFAULT " + column.defaultValue
+		}
+		if column.comment != "" {
+			def += " COMMENT " + quoteString(column.comment)
+		}
+		schema.Columns = append(schema.Columns, genmodel.ColumnSchema{Name: column.name, Definition: def})
+	}
+	if len(table.primaryKey) > 0 {
+		primaryKey := genmodel.IndexSchema{Name: genmodel.IndexPrimary, Kind: genmodel.IndexPrimary}
+		for _, name := range table.primaryKey {
+			primaryKey.Columns = append(primaryKey.Columns, genmodel.IndexColumn{Name: name})
+		}
+		schema.Indexes = append(schema.Indexes, primaryKey)
+	}
+	schema.Indexes = append(schema.Indexes, table.indexes...)
+	return schema.CreateStatement()
+}
+
+// renderSQLite renders the table as a SQLite CREATE TABLE statement followed
+// by CREATE INDEX statements. SQLite has no full-text or spatial indexes,
+// column comments or index prefix lengths; these are left out.
+func renderSQLite(table *tableDef) string {
+	inlinePrimaryKey := len(table.primaryKey) == 1
+	var defs []string
+	for _, column := range table.columns {
+		def := sqliteIdentifier(column.name) + " " + column.sqlType
+		if inlinePrimaryKey && column.name == table.primaryKey[0] {
+			def += " PRIMARY KEY"
+			if column.autoIncrement {
+				def += " AUTOINCREMENT"
+			}
+		}
+		if column.notNull && !(inlinePrimaryKey && column.name == table.primaryKey[0]) {
+			def += " NOT NULL"
+		}
+		if column.unique {
+			def += " UNIQUE"
+		}
+		if column.hasDefault {
+			def += " DEFAULT " + column.defaultValue
+		}
+		defs = append(defs, def)
+	}
+	if len(table.primaryKey) > 1 {
+		quoted := make([]string, 0, len(table.primaryKey))
+		for _, name := range table.primaryKey {
+			quoted = append(quoted, sqliteIdentifier(name))
+		}
+		defs = append(defs, "PRIMARY KEY ("+strings.Join(quoted, ",")+")")
+	}
+
+	var sb strings.Builder
+	sb.WriteString("CREATE TABLE " + sqliteIdentifier(table.name) + " (\n  ")
+	sb.WriteString(strings.Join(defs, ",\n  "))
+	sb.WriteString("\n);")
+	for _, index := range table.indexes {
+		if index.Kind == genmodel.IndexFulltext || index.Kind == genmodel.IndexSpatial {
+			fmt.Fprintf(&sb, "\n-- %s index %s is not supported by SQLite", index.Kind, index.Name)
+			continue
+		}
+		quoted := make([]string, 0, len(index.Columns))
+		for _, column := range index.Columns {
+			quoted = append(quoted, sqliteIdentifier(column.Name))
+		}
+		create := "CREATE INDEX "
+		if index.Kind == genmodel.IndexUnique {
+			create = "CREATE UNIQUE INDEX "
+		}
+		sb.WriteString("\n" + create + sqliteIdentifier(index.Name) + " ON " + sqliteIdentifier(table.name) +
+			" (" + strings.Join(quoted, ",") + ");")
+	}
+	return sb.String()
+}
+
+func sqliteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// renderTable renders the CREATE statements of table in dialect.
+func renderTable(table *tableDef, dialect string) string {
+	if dialect == service.DriverSqlite {
+		return renderSQLite(table)
+	}
+	return renderMySQL(table)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
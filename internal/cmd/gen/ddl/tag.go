@@ -0,0 +1,107 @@
+package ddl
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/jiajia556/godo/internal/utils"
+)
+
+// gormTag is a parsed gorm struct tag. Keys are upper-cased with underscores
+// and spaces removed, as GORM compares them, so "primary_key" and "NOT NULL"
+// read as PRIMARYKEY and NOTNULL. A key may repeat, as index does.
+type gormTag []tagSetting
+
+type tagSetting struct {
+	key   string
+	value string
+}
+
+func parseGormTag(structTag string) gormTag {
+	var tag gormTag
+	for _, part := range strings.Split(reflect.StructTag(structTag).Get("gorm"), ";") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(part, ":")
+		key = strings.NewReplacer("_", "", " ", "").Replace(strings.ToUpper(strings.TrimSpace(key)))
+		tag = append(tag, tagSetting{key: key, value: strings.TrimSpace(value)})
+	}
+	return tag
+}
+
+func (t gormTag) has(key string) bool {
+	for _, setting := range t {
+		if setting.key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// enabled reports whether a flag is set and not explicitly false.
+func (t gormTag) enabled(key string) bool {
+	return t.has(key) && !strings.EqualFold(t.value(key), "false")
+}
+
+func (t gormTag) value(key string) string {
+	for _, setting := range t {
+		if setting.key == key {
+			return setting.value
+		}
+	}
+	return ""
+}
+
+func (t gormTag) values(key string) []string {
+	var values []string
+	for _, setting := range t {
+		if setting.key == key {
+			values = append(values, setting.value)
+		}
+	}
+	return values
+}
+
+func (t *gormTag) set(key, value string) {
+	for i, setting := range *t {
+		if setting.key == key {
+			(*t)[i].value = value
+			return
+		}
+	}
+	*t = append(*t, tagSetting{key: key, value: value})
+}
+
+// ignored reports whether the field has no column: gorm:"-", "-:all" or
+// "-:migration".
+func (t gormTag) ignored() bool {
+	if !t.has("-") {
+		return false
+	}
+	switch strings.ToLower(t.value("-")) {
+	case "", "all", "migration":
+		return true
+	}
+	return false
+}
+
+// columnName returns the column of a field without a column tag, following
+// GORM's naming strategy.
+func columnName(fieldName string) string {
+	return utils.CamelToSnake(fieldName)
+}
+
+// defaultTableName returns the table of a model without a TableName method:
+// the snake_case plural of the struct name, as GORM names it.
+func defaultTableName(structName string) string {
+	name := columnName(structName)
+	switch {
+	case strings.HasSuffix(name, "s"), strings.HasSuffix(name, "x"), strings.HasSuffix(name, "z"),
+		strings.HasSuffix(name, "ch"), strings.HasSuffix(name, "sh"):
+		return name + "es"
+	case strings.HasSuffix(name, "y") && len(name) > 1 && !strings.ContainsRune("aeiou", rune(name[len(name)-2])):
+		return name[:len(name)-1] + "ies"
+	}
+	return name + "s"
+}
@@ -0,0 +1,26 @@
+package dto
+
+import "github.com/spf13/cobra"
+
+var dtoCmd = &cobra.Command{
+	Use:   "dto <model>",
+	Short: "Generate request and response DTOs for a model",
+	Long: "Generates internal/<cmd>/dto/<model>.go with query, create and update requests, a response\n" +
+		"struct and conversions for the model package internal/common/models/<model>. Binding tags\n" +
+		"are derived from the schema: required for NOT NULL columns without a default, max for\n" +
+		"VARCHAR lengths and oneof for ENUM values.",
+	Example: "  godo gen dto user\n  godo gen dto user --cmd admin-api",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmdName, _ := cmd.Flags().GetString("cmd")
+		return genDTO(cmdName, args[0])
+	},
+}
+
+func GetCommand() *cobra.Command {
+	return dtoCmd
+}
+
+func init() {
+	dtoCmd.Flags().StringP("cmd", "", "", "The cmd that uses the DTOs, e.g. 'admin-api'")
+}
@@ -0,0 +1,216 @@
+package dto
+
+import (
+	"fmt"
+	"go/types"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	genmodel "github.com/jiajia556/godo/internal/cmd/gen/model"
+	"github.com/jiajia556/godo/internal/service"
+	"github.com/jiajia556/godo/internal/template"
+	"github.com/jiajia556/godo/internal/utils"
+	"github.com/jiajia556/godo/templates"
+)
+
+// TemplatePath is the template of a model's DTO file.
+const TemplatePath = "default/internal/default-api/dto/dto.go.templ"
+
+var formatGoFiles = utils.FormatGoFiles
+
+func genDTO(cmdName, modelPkg string) error {
+	var err error
+	if cmdName == "" {
+		cmdName, err = service.GetDefaultCmd()
+		if err != nil {
+			return fmt.Errorf("get default command: %w", err)
+		}
+	}
+	if err := service.ValidateCmdName(cmdName); err != nil {
+		return fmt.Errorf("validate command name: %w", err)
+	}
+	if !service.IsCmdExists(cmdName) {
+		return fmt.Errorf("command %q does not exist", cmdName)
+	}
+	if err := service.RequireCmdType(cmdName, service.CmdTypeAPI); err != nil {
+		return err
+	}
+
+	info, err := genmodel.LoadModelInfo(modelPkg)
+	if err != nil {
+		return fmt.Errorf("load model %s: %w", modelPkg, err)
+	}
+	base := path.Base(modelPkg)
+	data, err := TemplateData(info, info.StructName)
+	if err != nil {
+		return err
+	}
+	dtoPath, err := service.GetAbsPath(filepath.Join("internal", cmdName, "dto", base+".go"))
+	if err != nil {
+		return fmt.Errorf("resolve dto file: %w", err)
+	}
+	if utils.IsFileExists(dtoPath) {
+		return fmt.Errorf("dto file already exists: %s", dtoPath)
+	}
+	tmplContent, err := templates.TemplateFS.ReadFile(TemplatePath)
+	if err != nil {
+		return fmt.Errorf("read dto template: %w", err)
+	}
+	if err = template.CreateFile(string(tmplContent), data, dtoPath); err != nil {
+		return fmt.Errorf("write dto file: %w", err)
+	}
+	if err = formatGoFiles(dtoPath); err != nil {
+		return fmt.Errorf("format dto file: %w", err)
+	}
+	return nil
+}
+
+// TemplateData builds the DTOs of model, named after name. Primary keys
+// identify records; auto-incremented keys and fields GORM manages cannot be
+// set by clients, and soft delete markers are not returned. Request fields get
+// binding tags from the column definitions.
+func TemplateData(info *genmodel.ModelInfo, name string) (template.DTOData, error) {
+	keys := info.PrimaryKey()
+	if len(keys) == 0 {
+		return template.DTOData{}, fmt.Errorf("model %s has no primary key", info.StructName)
+	}
+	data := template.DTOData{
+		Name:            name,
+		ModelPkg:        info.Package,
+		ModelImportPath: info.ImportPath,
+		ModelStructName: info.StructName,
+	}
+	for _, key := range keys {
+		field := dtoField(key)
+		field.Binding = "required"
+		data.KeyFields = append(data.KeyFields, field)
+	}
+
+	imports := map[string]bool{info.ImportPath: true}
+	for _, field := range info.Fields {
+		if isSoftDelete(field) || field.Hidden {
+			continue
+		}
+		data.RespFields = append(data.RespFields, dtoField(field))
+		for _, importPath := range field.Imports {
+			imports[importPath] = true
+		}
+		if field.Managed {
+			continue
+		}
+		if isFilterable(field) && !field.PrimaryKey {
+			query := dtoField(field)
+			query.Type = "*" + strings.TrimPrefix(field.Type, "*")
+			query.Binding = bindingTag(field, false)
+			data.QueryFields = append(data.QueryFields, query)
+		}
+		if !field.PrimaryKey || !field.AutoIncrement {
+			data.CreateFields = append(data.CreateFields, inputField(field))
+		}
+		if !field.PrimaryKey {
+			data.UpdateFields = append(data.UpdateFields, inputField(field))
+		}
+	}
+	for importPath := range imports {
+		data.Imports = append(data.Imports, importPath)
+	}
+	sort.Strings(data.Imports)
+	return data, nil
+}
+
+func dtoField(field genmodel.ModelField) template.DTOField {
+	return template.DTOField{Name: field.Name, Type: field.Type, JSON: field.JSON, Column: field.Column}
+}
+
+// inputField returns the request field clients set field with. Fields of NOT
+// NULL columns without a default are required; booleans and numbers become
+// pointers so that required accepts false and zero.
+func inputField(field genmodel.ModelField) template.DTOField {
+	input := dtoField(field)
+	required := field.NotNull && !field.HasDefault && !field.AutoIncrement
+	input.Binding = bindingTag(field, required)
+	if required && isBoolOrNumber(field.Type) {
+		input.Type = "*" + field.Type
+		input.Deref = true
+	}
+	return input
+}
+
+// bindingTag returns the validation rules of field for gin's binding tag: max
+// for CHAR and VARCHAR lengths and oneof for ENUM and SET values.
+func bindingTag(field genmodel.ModelField, required bool) string {
+	base := strings.TrimPrefix(field.Type, "*")
+	var rules []string
+	if field.MaxLength > 0 && base == "string" {
+		rules = append(rules, "max="+strconv.Itoa(field.MaxLength))
+	}
+	if values, ok := oneOf(field.Enum); ok {
+		if field.Set {
+			rules = append(rules, "dive")
+		}
+		rules = append(rules, "oneof="+values)
+	}
+	switch {
+	case required:
+		rules = append([]string{"required"}, rules...)
+	case len(rules) > 0:
+		rules = append([]string{"omitempty"}, rules...)
+	}
+	return strings.Join(rules, ",")
+}
+
+// oneOf renders values as the parameter of the oneof validator. Values the
+// validator or a struct tag cannot hold, such as empty strings or values with
+// commas or quotes, leave the field without the rule.
+func oneOf(values []string) (string, bool) {
+	if len(values) == 0 {
+		return "", false
+	}
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		if value == "" || strings.ContainsAny(value, ",|'\"`\\") {
+			return "", false
+		}
+		if strings.ContainsAny(value, " \t") {
+			value = "'" + value + "'"
+		}
+		quoted = append(quoted, value)
+	}
+	return strings.Join(quoted, " "), true
+}
+
+// isFilterable reports whether lists can be filtered by equality on field:
+// strings, integers, booleans and ENUM values.
+func isFilterable(field genmodel.ModelField) bool {
+	base := strings.TrimPrefix(field.Type, "*")
+	if len(field.Enum) > 0 {
+		return !field.Set
+	}
+	basic, ok := builtinType(base)
+	return ok && basic.Info()&(types.IsString|types.IsInteger|types.IsBoolean) != 0
+}
+
+func isBoolOrNumber(typeName string) bool {
+	basic, ok := builtinType(typeName)
+	return ok && basic.Info()&(types.IsBoolean|types.IsNumeric) != 0
+}
+
+func builtinType(typeName string) (*types.Basic, bool) {
+	obj, ok := types.Universe.Lookup(typeName).(*types.TypeName)
+	if !ok {
+		return nil, false
+	}
+	basic, ok := obj.Type().(*types.Basic)
+	return basic, ok
+}
+
+func isSoftDelete(field genmodel.ModelField) bool {
+	switch strings.TrimPrefix(field.Type, "*") {
+	case "gorm.DeletedAt", "soft_delete.DeletedAt":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,183 @@
+package dto
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	genmodel "github.com/jiajia556/godo/internal/cmd/gen/model"
+	"github.com/jiajia556/godo/internal/template"
+)
+
+func TestTemplateDataDerivesBindingTags(t *testing.T) {
+	info := &genmodel.ModelInfo{
+		Package:    "user",
+		ImportPath: "example.com/project/internal/common/models/user",
+		StructName: "User",
+		TableName:  "user",
+		Fields: []genmodel.ModelField{
+			{Name: "Id", Type: "uint64", Column: "id", JSON: "id", PrimaryKey: true, AutoIncrement: true, NotNull: true},
+			{Name: "Name", Type: "string", Column: "name", JSON: "name", NotNull: true, MaxLength: 64},
+			{Name: "Nick", Type: "*string", Column: "nick", JSON: "nick", MaxLength: 32},
+			{Name: "Age", Type: "int32", Column: "age", JSON: "age", NotNull: true},
+			{Name: "Status", Type: "user.UserStatus", Imports: []string{"example.com/project/internal/common/models/user"},
+				Column: "status", JSON: "status", NotNull: true, HasDefault: true, Enum: []string{"active", "banned"}},
+			{Name: "Tags", Type: "user.UserTagsSet", Imports: []string{"example.com/project/internal/common/models/user"},
+				Column: "tags", JSON: "tags", Enum: []string{"new", "long term"}, Set: true},
+			{Name: "CreatedAt", Type: "time.Time", Imports: []string{"time"}, Column: "created_at", JSON: "created_at", Managed: true},
+			{Name: "DeletedAt", Type: "gorm.DeletedAt", Imports: []string{"gorm.io/gorm"}, Column: "deleted_at", JSON: "deleted_at", Managed: true},
+		},
+	}
+
+	data, err := TemplateData(info, "User")
+	if err != nil {
+		t.Fatalf("TemplateData() error = %v", err)
+	}
+	if want := []string{"example.com/project/internal/common/models/user", "time"}; !reflect.DeepEqual(data.Imports, want) {
+		t.Fatalf("Imports = %v, want %v", data.Imports, want)
+	}
+	if want := []template.DTOField{{Name: "Id", Type: "uint64", JSON: "id", Column: "id", Binding: "required"}}; !reflect.DeepEqual(data.KeyFields, want) {
+		t.Fatalf("KeyFields = %+v", data.KeyFields)
+	}
+	bindings := func(fields []template.DTOField) map[string]string {
+		result := make(map[string]string)
+		for _, field := range fields {
+			result[field.Name+" "+field.Type] = field.Binding
+		}
+		return result
+	}
+	wantCreate := map[string]string{
+		"Name string":            "required,max=64",
+		"Nick *string":           "omitempty,max=32",
+		"Age *int32":             "required",
+		"Status user.UserStatus": "omitempty,oneof=active banned",
+		"Tags user.UserTagsSet":  "omitempty,dive,oneof=new 'long term'",
+	}
+	if got := bindings(data.CreateFields); !reflect.DeepEqual(got, wantCreate) {
+		t.Fatalf("CreateFields = %v, want %v", got, wantCreate)
+	}
+	if got := bindings(data.UpdateFields); !reflect.DeepEqual(got, wantCreate) {
+		t.Fatalf("UpdateFields = %v, want %v", got, wantCreate)
+	}
+	wantQuery := map[string]string{
+		"Name *string":            "omitempty,max=64",
+		"Nick *string":            "omitempty,max=32",
+		"Age *int32":              "",
+		"Status *user.UserStatus": "omitempty,oneof=active banned",
+	}
+	if got := bindings(data.QueryFields); !reflect.DeepEqual(got, wantQuery) {
+		t.Fatalf("QueryFields = %v, want %v", got, wantQuery)
+	}
+	for _, field := range data.CreateFields {
+		if field.Deref != (field.Name == "Age") {
+			t.Fatalf("field %s Deref = %v", field.Name, field.Deref)
+		}
+	}
+	if len(data.RespFields) != 7 || data.RespFields[6].Name != "CreatedAt" {
+		t.Fatalf("RespFields = %+v", data.RespFields)
+	}
+
+	if _, err := TemplateData(&genmodel.ModelInfo{StructName: "Log"}, "Log"); err == nil || !strings.Contains(err.Error(), "no primary key") {
+		t.Fatalf("keyless TemplateData() error = %v", err)
+	}
+}
+
+func TestOneOfSkipsInexpressibleValues(t *testing.T) {
+	for _, values := range [][]string{nil, {"a", ""}, {"a,b"}, {"it's"}} {
+		if got, ok := oneOf(values); ok {
+			t.Errorf("oneOf(%q) = %q, want no rule", values, got)
+		}
+	}
+}
+
+func TestGenDTOWritesModelDTOs(t *testing.T) {
+	root := t.TempDir()
+	config := `{
+  "project_name": "example.com/project",
+  "default_cmd": "api",
+  "cmd_types": {"api": "api"}
+}`
+	model := "package user\n\n" +
+		"type UserStatus string\n\n" +
+		"type User struct {\n" +
+		"\tId     uint64     `gorm:\"column:id;autoIncrement;primaryKey\" json:\"id\"`\n" +
+		"\tName   string     `gorm:\"column:name;notNull\" json:\"name\"`\n" +
+		"\tStatus UserStatus `gorm:\"column:status;default:active;notNull\" json:\"status\"`\n" +
+		"}\n\n" +
+		"func (data *User) TableName() string {\n\treturn \"user\"\n}\n\n" +
+		"func (data *User) GetCreateDDL() string {\n\treturn \"CREATE TABLE user (id bigint unsigned NOT NULL AUTO_INCREMENT, " +
+		"name varchar(64) NOT NULL, status enum('active','banned') NOT NULL DEFAULT 'active', PRIMARY KEY (id))\"\n}\n"
+	for path, content := range map[string]string{
+		filepath.Join(root, "godoconfig.json"):                                  config,
+		filepath.Join(root, "internal", "common", "models", "user", "model.go"): model,
+		filepath.Join(root, "internal", "common", "models", "userrole", "model.go"): "package userrole\n\n" +
+			"type UserRole struct {\n\tUserId uint64 `gorm:\"column:user_id;primaryKey\" json:\"user_id\"`\n" +
+			"\tRole string `gorm:\"column:role;primaryKey\" json:\"role\"`\n}\n\n" +
+			"func (data *UserRole) TableName() string {\n\treturn \"user_role\"\n}\n",
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(root, "cmd", "api"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GOD_PROJECT_ROOT", root)
+	previousFormatter := formatGoFiles
+	var formatted []string
+	formatGoFiles = func(paths ...string) error {
+		formatted = append(formatted, paths...)
+		return nil
+	}
+	t.Cleanup(func() { formatGoFiles = previousFormatter })
+
+	if err := genDTO("", "user"); err != nil {
+		t.Fatalf("genDTO() error = %v", err)
+	}
+	dtoPath := filepath.Join(root, "internal", "api", "dto", "user.go")
+	content, err := os.ReadFile(dtoPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), dtoPath, content, 0); err != nil {
+		t.Fatalf("generated DTO does not parse: %v\n%s", err, content)
+	}
+	for _, expected := range []string{
+		"type UserQueryReq struct {",
+		"Name *string `form:\"name\" json:\"name\" binding:\"omitempty,max=64\"`",
+		"type UserCreateReq struct {\n\tName string `form:\"name\" json:\"name\" binding:\"required,max=64\"`\n" +
+			"\tStatus user.UserStatus `form:\"status\" json:\"status\" binding:\"omitempty,oneof=active banned\"`\n}",
+		"func (req *UserCreateReq) ToModel() *user.User {",
+		"func (req *UserUpdateReq) ApplyTo(data *user.User) {",
+		"func NewUserRespList(list []*user.User) []UserResp {",
+	} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("DTO does not contain %q:\n%s", expected, content)
+		}
+	}
+	if len(formatted) != 1 || formatted[0] != dtoPath {
+		t.Fatalf("formatted paths = %v", formatted)
+	}
+	if err := genDTO("api", "user"); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("duplicate dto error = %v", err)
+	}
+
+	// DTOs are named after the model struct, not its package directory.
+	if err := genDTO("api", "userrole"); err != nil {
+		t.Fatalf("genDTO(userrole) error = %v", err)
+	}
+	content, err = os.ReadFile(filepath.Join(root, "internal", "api", "dto", "userrole.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "type UserRoleQueryReq struct {") || strings.Contains(string(content), "Userrole") {
+		t.Fatalf("userrole DTO is not named after the model:\n%s", content)
+	}
+}
@@ -3,7 +3,10 @@ package gen
 import (
 	"github.com/jiajia556/godo/internal/cmd/gen/act"
 	"github.com/jiajia556/godo/internal/cmd/gen/cmd"
+	"github.com/jiajia556/godo/internal/cmd/gen/crud"
 	"github.com/jiajia556/godo/internal/cmd/gen/ctrl"
+	"github.com/jiajia556/godo/internal/cmd/gen/ddl"
+	"github.com/jiajia556/godo/internal/cmd/gen/dto"
 	"github.com/jiajia556/godo/internal/cmd/gen/mdw"
 	"github.com/jiajia556/godo/internal/cmd/gen/model"
 	"github.com/jiajia556/godo/internal/cmd/gen/rt"
@@ -24,9 +27,12 @@ func init() {
 	genCmd.AddCommand(
 		cmd.GetCommand(),
 		ctrl.GetCommand(),
+		crud.GetCommand(),
 		act.GetCommand(),
 		rt.GetCommand(),
 		mdw.GetCommand(),
 		model.GetCommand(),
+		ddl.GetCommand(),
+		dto.GetCommand(),
 	)
 }
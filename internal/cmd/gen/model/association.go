@@ -0,0 +1,181 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jiajia556/godo/internal/utils"
+)
+
+// Association modes of gen model --associations.
+const (
+	AssociationsBelongsTo = "belongs-to"
+	AssociationsHasMany   = "has-many"
+	AssociationsNone      = "none"
+)
+
+// modelRef is what association planning needs to know about a table.
+type modelRef struct {
+	tableName  string
+	structName string
+	pkg        string
+	// taken holds the field names already used in the struct.
+	taken       map[string]bool
+	foreignKeys []ForeignKeySchema
+}
+
+// planAssociations derives association fields from the single-column foreign
+// keys between the given tables and returns them keyed by table name.
+//
+// Every model lives in its own package, so two tables can only be linked in one
+// direction without an import cycle: mode selects belongs-to fields on the
+// referencing model or has-many fields on the referenced one. Self-references
+// get both. Links that would close an import cycle are skipped with a notice.
+// modelsPath is the import path of the directory holding the model packages.
+func planAssociations(createTables []string, mode, prefix, modelsPath string) (map[string][]fieldInfo, error) {
+	switch mode {
+	case "":
+		mode = AssociationsBelongsTo
+	case AssociationsNone:
+		return nil, nil
+	case AssociationsBelongsTo, AssociationsHasMany:
+	default:
+		return nil, fmt.Errorf("unsupported associations mode %q; expected %s, %s or %s", mode, AssociationsBelongsTo, AssociationsHasMany, AssociationsNone)
+	}
+
+	refs := make([]*modelRef, 0, len(createTables))
+	byTable := make(map[string]*modelRef, len(createTables))
+	for _, createTable := range createTables {
+		table, err := ParseCreateTable(createTable)
+		if err != nil {
+			return nil, fmt.Errorf("parse CREATE TABLE statement: %w", err)
+		}
+		structName := modelStructName(table.Name, prefix)
+		ref := &modelRef{
+			tableName:   table.Name,
+			structName:  structName,
+			pkg:         modelPackageName(structName),
+			taken:       make(map[string]bool, len(table.Columns)),
+			foreignKeys: table.ForeignKeys(),
+		}
+		for _, column := range table.Columns {
+			ref.taken[toCamelCase(column.Name)] = true
+		}
+		refs = append(refs, ref)
+		byTable[strings.ToLower(table.Name)] = ref
+	}
+
+	associations := make(map[string][]fieldInfo)
+	imports := make(map[string]map[string]bool)
+	for _, child := range refs {
+		linksTo := make(map[*modelRef]int)
+		for _, fk := range child.foreignKeys {
+			if parent := byTable[strings.ToLower(fk.RefTable)]; parent != nil && len(fk.Columns) == 1 {
+				linksTo[parent]++
+			}
+		}
+		for _, fk := range child.foreignKeys {
+			parent := byTable[strings.ToLower(fk.RefTable)]
+			if parent == nil || len(fk.Columns) != 1 || len(fk.RefColumns) != 1 {
+				continue
+			}
+			tags := fmt.Sprintf("foreignKey:%s;references:%s", toCamelCase(fk.Columns[0]), toCamelCase(fk.RefColumns[0]))
+			self := parent == child
+
+			if mode == AssociationsBelongsTo || self {
+				if !self && importsReach(imports, parent.pkg, child.pkg) {
+					utils.OutputInfof("skip belongs-to %s.%s: %s already depends on %s", child.tableName, fk.Columns[0], parent.pkg, child.pkg)
+				} else if name := child.claim(belongsToName(fk.Columns[0], parent.structName), "Ref"); name != "" {
+					associations[child.tableName] = append(associations[child.tableName], associationField(name, "*", parent, child, tags, modelsPath))
+					addImport(imports, child.pkg, parent.pkg)
+				}
+			}
+			if mode == AssociationsHasMany || self {
+				if !self && importsReach(imports, child.pkg, parent.pkg) {
+					utils.OutputInfof("skip has-many %s -> %s: %s already depends on %s", parent.tableName, child.tableName, child.pkg, parent.pkg)
+				} else {
+					name := child.structName
+					if self {
+						name = "Children"
+					}
+					if linksTo[parent] > 1 {
+						name += "By" + toCamelCase(fk.Columns[0])
+					}
+					if name = parent.claim(name, "List"); name != "" {
+						associations[parent.tableName] = append(associations[parent.tableName], associationField(name, "[]", child, parent, tags, modelsPath))
+						addImport(imports, parent.pkg, child.pkg)
+					}
+				}
+			}
+		}
+	}
+	return associations, nil
+}
+
+// belongsToName names the field holding the referenced record: user_id becomes
+// User, and a column without an _id suffix such as created_by becomes
+// CreatedByUsers.
+func belongsToName(column, parentStruct string) string {
+	lower := strings.ToLower(column)
+	if strings.HasSuffix(lower, "_id") && len(column) > len("_id") {
+		return toCamelCase(column[:len(column)-len("_id")])
+	}
+	return toCamelCase(column) + parentStruct
+}
+
+// claim reserves name in the struct, appending suffix once on a clash. It
+// returns "" when both names are taken.
+func (r *modelRef) claim(name, suffix string) string {
+	for _, candidate := range []string{name, name + suffix} {
+		if !r.taken[candidate] {
+			r.taken[candidate] = true
+			return candidate
+		}
+	}
+	return ""
+}
+
+// associationField builds the field declared in owner that refers to target.
+func associationField(name, kind string, target, owner *modelRef, tags, modelsPath string) fieldInfo {
+	f := fieldInfo{
+		name:     name,
+		typeName: kind + target.structName,
+		gormTags: tags,
+		jsonTag:  utils.CamelToSnake(name) + ",omitempty",
+	}
+	if target.pkg != owner.pkg {
+		f.typeName = kind + target.pkg + "." + target.structName
+		f.importPath = modelsPath + "/" + target.pkg
+	}
+	return f
+}
+
+func addImport(imports map[string]map[string]bool, from, to string) {
+	if from == to {
+		return
+	}
+	if imports[from] == nil {
+		imports[from] = make(map[string]bool)
+	}
+	imports[from][to] = true
+}
+
+// importsReach reports whether package from already depends on package to.
+func importsReach(imports map[string]map[string]bool, from, to string) bool {
+	seen := map[string]bool{from: true}
+	queue := []string{from}
+	for len(queue) > 0 {
+		pkg := queue[0]
+		queue = queue[1:]
+		if pkg == to {
+			return true
+		}
+		for next := range imports[pkg] {
+			if !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+var associationTables = []string{
+	"CREATE TABLE `users` (`id` bigint NOT NULL, `name` varchar(64), PRIMARY KEY (`id`));",
+	"CREATE TABLE `orders` (`id` bigint NOT NULL, `user_id` bigint NOT NULL, `created_by` bigint, PRIMARY KEY (`id`), " +
+		"CONSTRAINT `fk_orders_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`), " +
+		"FOREIGN KEY (`created_by`) REFERENCES `users` (`id`) ON DELETE SET NULL);",
+	"CREATE TABLE `categories` (`id` bigint NOT NULL, `parent_id` bigint, PRIMARY KEY (`id`), " +
+		"FOREIGN KEY (`parent_id`) REFERENCES `categories` (`id`));",
+}
+
+func TestPlanAssociationsBelongsTo(t *testing.T) {
+	associations, err := planAssociations(associationTables, AssociationsBelongsTo, "", "example.com/project/internal/common/models")
+	if err != nil {
+		t.Fatalf("planAssociations() error = %v", err)
+	}
+	orders := associations["orders"]
+	if len(orders) != 2 || len(associations["users"]) != 0 {
+		t.Fatalf("associations = %+v", associations)
+	}
+	if orders[0].name != "User" || orders[0].typeName != "*users.Users" ||
+		orders[0].gormTags != "foreignKey:UserId;references:Id" ||
+		orders[0].importPath != "example.com/project/internal/common/models/users" ||
+		orders[0].jsonTag != "user,omitempty" {
+		t.Fatalf("belongs-to field = %+v", orders[0])
+	}
+	if orders[1].name != "CreatedByUsers" {
+		t.Fatalf("second belongs-to field = %+v", orders[1])
+	}
+
+	categories := associations["categories"]
+	if len(categories) != 2 || categories[0].name != "Parent" || categories[0].typeName != "*Categories" ||
+		categories[1].name != "Children" || categories[1].typeName != "[]Categories" || categories[1].importPath != "" {
+		t.Fatalf("self-reference fields = %+v", categories)
+	}
+
+	model, err := buildModel(associationTables[1], StructOptions{associations: associations})
+	if err != nil {
+		t.Fatalf("buildModel() error = %v", err)
+	}
+	if !strings.Contains(model.structText, "User     *users.Users") || len(model.imports) != 1 {
+		t.Fatalf("struct = %s\nimports = %v", model.structText, model.imports)
+	}
+}
+
+func TestPlanAssociationsHasMany(t *testing.T) {
+	associations, err := planAssociations(associationTables, AssociationsHasMany, "", "example.com/project/internal/common/models")
+	if err != nil {
+		t.Fatalf("planAssociations() error = %v", err)
+	}
+	users := associations["users"]
+	if len(users) != 2 || len(associations["orders"]) != 0 {
+		t.Fatalf("associations = %+v", associations)
+	}
+	if users[0].name != "OrdersByUserId" || users[0].typeName != "[]orders.Orders" || users[0].gormTags != "foreignKey:UserId;references:Id" {
+		t.Fatalf("has-many field = %+v", users[0])
+	}
+}
+
+func TestPlanAssociationsSkipsImportCycles(t *testing.T) {
+	tables := []string{
+		"CREATE TABLE `teams` (`id` bigint, `owner_id` bigint, FOREIGN KEY (`owner_id`) REFERENCES `members` (`id`));",
+		"CREATE TABLE `members` (`id` bigint, `team_id` bigint, FOREIGN KEY (`team_id`) REFERENCES `teams` (`id`));",
+	}
+	associations, err := planAssociations(tables, AssociationsBelongsTo, "", "example.com/project/internal/common/models")
+	if err != nil {
+		t.Fatalf("planAssociations() error = %v", err)
+	}
+	if len(associations["teams"]) != 1 || len(associations["members"]) != 0 {
+		t.Fatalf("associations = %+v", associations)
+	}
+
+	if associations, err := planAssociations(tables, AssociationsNone, "", ""); err != nil || associations != nil {
+		t.Fatalf("none = %+v, %v", associations, err)
+	}
+	if _, err := planAssociations(tables, "both", "", ""); err == nil {
+		t.Fatal("planAssociations() accepted an unknown mode")
+	}
+}
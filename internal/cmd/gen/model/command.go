@@ -1,18 +1,68 @@
 package model
 
-import "github.com/spf13/cobra"
+import (
+	"github.com/jiajia556/godo/internal/service"
+	"github.com/spf13/cobra"
+)
 
 var modelCmd = &cobra.Command{
 	Use:     "model",
 	Short:   "Generate database model files",
-	Long:    "Generate Go model files from SQL schema definitions or from existing database.\nCreates record and list type files based on SQL CREATE TABLE statements.",
-	Example: "  godo gen model config.json\n  godo gen model schema.sql",
-	Args:    cobra.ExactArgs(1),
+	Long:    "Generate Go model files from SQL schema definitions or from existing database, given by a config file or a DSN.\nCreates record and list type files based on SQL CREATE TABLE statements.\nSQL files and directories of them are replayed in order: CREATE, ALTER, DROP and RENAME TABLE\nstatements are applied to an in-memory schema and models are generated from the final tables.\nDirectories are read in file name order (by leading version number), skipping .down.sql files.\nA schema snapshot saved with --snapshot regenerates the models without a database.",
+	Example: "  godo gen model config.json\n  godo gen model --from-db config.yaml --snapshot schema.lock.json\n  godo gen model schema.lock.json\n  godo gen model schema.sql\n  godo gen model migrations/\n  godo gen model 001_init.sql 002_add_email.sql\n  godo gen model schema.sql --update\n  godo gen model schema.sql --prefix app_\n  godo gen model schema.sql --json-case lowerCamel --json-omitempty\n  godo gen model config.yaml --db analytics\n  godo gen model --dsn 'user:pass@tcp(db:3306)/app' --schema reporting\n  GODO_DSN='user:pass@unix(/run/mysqld/mysqld.sock)/app' godo gen model",
+	Args:    cobra.ArbitraryArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return genModel(args[0])
+		update, _ := cmd.Flags().GetBool("update")
+		prefix, _ := cmd.Flags().GetString("prefix")
+		commentTag, _ := cmd.Flags().GetBool("comment-tag")
+		associations, _ := cmd.Flags().GetString("associations")
+		jsonCase, _ := cmd.Flags().GetString("json-case")
+		jsonOmitEmpty, _ := cmd.Flags().GetBool("json-omitempty")
+		database, _ := cmd.Flags().GetString("db")
+		dsn, _ := cmd.Flags().GetString("dsn")
+		schema, _ := cmd.Flags().GetString("schema")
+		jobs, _ := cmd.Flags().GetInt("jobs")
+		fromDB, _ := cmd.Flags().GetString("from-db")
+		snapshot, _ := cmd.Flags().GetString("snapshot")
+		paths, dsn, err := modelSource(args, dsn, fromDB)
+		if err != nil {
+			return err
+		}
+		return genModel(paths, generateOptions{
+			Update:            update,
+			Associations:      associations,
+			Database:          database,
+			DSN:               dsn,
+			Schema:            schema,
+			Jobs:              jobs,
+			Snapshot:          snapshot,
+			SaveJSONCase:      cmd.Flags().Changed("json-case"),
+			SaveJSONOmitEmpty: cmd.Flags().Changed("json-omitempty"),
+			StructOptions: StructOptions{
+				Prefix:        prefix,
+				CommentTag:    commentTag,
+				JSONCase:      jsonCase,
+				JSONOmitEmpty: jsonOmitEmpty,
+			},
+		})
 	},
 }
 
 func GetCommand() *cobra.Command {
 	return modelCmd
 }
+
+func init() {
+	modelCmd.Flags().BoolP("update", "u", false, "Rewrite the generated struct, TableName and GetCreateDDL in existing model.go files")
+	modelCmd.Flags().String("prefix", "", "Table prefix stripped from struct and package names (defaults to mysql.prefix of the config file)")
+	modelCmd.Flags().Bool("comment-tag", false, "Also add column comments as gorm comment tags so CreateTableIfNotExists keeps them")
+	modelCmd.Flags().String("associations", AssociationsBelongsTo, "Association fields generated from foreign keys: belongs-to, has-many or none")
+	modelCmd.Flags().String("json-case", service.JSONCaseSnake, "JSON tag naming: snake, camel, lowerCamel or original (saved to godoconfig.json)")
+	modelCmd.Flags().String("db", "", "Named database the models belong to: they go to internal/common/models/<db>/<pkg>, use its sessions and are read from databases.<db> of a config file")
+	modelCmd.Flags().String("dsn", "", "Read the tables from the MySQL database of a go-sql-driver/mysql DSN instead of a file (defaults to $"+dsnEnv+")")
+	modelCmd.Flags().String("schema", "", "Schema to read the tables from when it differs from the default schema of the connection")
+	modelCmd.Flags().String("from-db", "", "Read the tables from the database described by a config file")
+	modelCmd.Flags().String("snapshot", "", "Save the tables read to a schema snapshot file (e.g. schema.lock.json) that gen model can later read without a database")
+	modelCmd.Flags().IntP("jobs", "j", 0, "Number of tables introspected and generated at once (defaults to the number of CPUs)")
+	modelCmd.Flags().Bool("json-omitempty", false, "Add omitempty to the JSON tags of nullable columns (saved to godoconfig.json)")
+}
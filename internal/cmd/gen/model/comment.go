@@ -0,0 +1,108 @@
+package model
+
+import (
+	"strings"
+	"unicode"
+)
+
+// extractComment returns the unescaped text of the COMMENT clause of a column
+// definition or of table options (COMMENT 'x' or COMMENT='x'). COMMENT inside
+// quoted text, such as a DEFAULT value, is ignored.
+func extractComment(def string) string {
+	var quote byte
+	for i := 0; i < len(def); i++ {
+		ch := def[i]
+		if quote != 0 {
+			if ch == '\\' && quote != '`' {
+				i++
+			} else if ch == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch ch {
+		case '\'', '"', '`':
+			quote = ch
+			continue
+		}
+		if !hasKeywordAt(def, i, "COMMENT") {
+			continue
+		}
+		j := i + len("COMMENT")
+		for j < len(def) && (def[j] == ' ' || def[j] == '\t' || def[j] == '\n' || def[j] == '\r' || def[j] == '=') {
+			j++
+		}
+		if j < len(def) && (def[j] == '\'' || def[j] == '"') {
+			text, _ := unquoteSQLString(def[j:])
+			return text
+		}
+	}
+	return ""
+}
+
+// hasKeywordAt reports whether keyword starts at s[i] as a whole word.
+func hasKeywordAt(s string, i int, keyword string) bool {
+	if i+len(keyword) > len(s) || !strings.EqualFold(s[i:i+len(keyword)], keyword) {
+		return false
+	}
+	if i > 0 && isIdentByte(s[i-1]) {
+		return false
+	}
+	end := i + len(keyword)
+	return end == len(s) || !isIdentByte(s[end])
+}
+
+func isIdentByte(ch byte) bool {
+	return ch == '_' || ch == '$' || ch >= '0' && ch <= '9' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z'
+}
+
+// unquoteSQLString decodes the quoted SQL string literal at the start of s and
+// reports whether it was terminated.
+func unquoteSQLString(s string) (string, bool) {
+	quote := s[0]
+	var sb strings.Builder
+	for i := 1; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case ch == '\\' && i+1 < len(s):
+			i++
+			switch s[i] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			case '0':
+			default:
+				sb.WriteByte(s[i])
+			}
+		case ch == quote && i+1 < len(s) && s[i+1] == quote:
+			sb.WriteByte(quote)
+			i++
+		case ch == quote:
+			return sb.String(), true
+		default:
+			sb.WriteByte(ch)
+		}
+	}
+	return sb.String(), false
+}
+
+// writeDocComment writes comment as // lines prefixed with indent.
+func writeDocComment(sb *strings.Builder, indent, comment string) {
+	for _, line := range strings.Split(strings.TrimSpace(comment), "\n") {
+		line = strings.TrimRightFunc(line, unicode.IsSpace)
+		if line == "" {
+			sb.WriteString(indent + "//\n")
+			continue
+		}
+		sb.WriteString(indent + "// " + line + "\n")
+	}
+}
+
+// commentTagValue makes a comment safe to embed in a gorm struct tag.
+func commentTagValue(comment string) string {
+	comment = strings.Join(strings.Fields(comment), " ")
+	return strings.NewReplacer(`"`, "'", "`", "'", ";", ",", `\`, "/").Replace(comment)
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestExtractCommentHandlesQuotingAndTableOptions(t *testing.T) {
+	tests := []struct{ def, want string }{
+		{"varchar(64) NOT NULL COMMENT 'user''s name'", "user's name"},
+		{`int COMMENT "line one\nline two"`, "line one\nline two"},
+		{"varchar(64) DEFAULT 'COMMENT ''nope''' COMMENT 'real'", "real"},
+		{"ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='User accounts'", "User accounts"},
+		{"int NOT NULL", ""},
+		{"varchar(64) COMMENTS 'x'", ""},
+	}
+	for _, tt := range tests {
+		if got := extractComment(tt.def); got != tt.want {
+			t.Errorf("extractComment(%q) = %q, want %q", tt.def, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateModelStructEmitsDocCommentsAndCommentTags(t *testing.T) {
+	ddl := "CREATE TABLE `users` (\n" +
+		"`id` bigint NOT NULL COMMENT 'Primary key',\n" +
+		"`name` varchar(64) COMMENT 'Display name; shown \"as is\"',\n" +
+		"PRIMARY KEY (`id`)\n" +
+		") ENGINE=InnoDB COMMENT='User accounts\\nOne row per login';"
+
+	generated, _, _, err := GenerateModelStruct(ddl, StructOptions{CommentTag: true})
+	if err != nil {
+		t.Fatalf("GenerateModelStruct() error = %v", err)
+	}
+	for _, want := range []string{
+		"// User accounts\n// One row per login\ntype Users struct {",
+		"    // Primary key\n    Id ",
+		"comment:Primary key\"",
+		"comment:Display name, shown 'as is'\"",
+	} {
+		if !strings.Contains(generated, want) {
+			t.Errorf("generated struct does not contain %q:\n%s", want, generated)
+		}
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "model.go", "package users\n\n"+generated, 0); err != nil {
+		t.Fatalf("generated struct does not parse: %v\n%s", err, generated)
+	}
+
+	generated, _, _, err = GenerateModelStruct(ddl, StructOptions{})
+	if err != nil || strings.Contains(generated, "comment:") || !strings.Contains(generated, "// Primary key") {
+		t.Fatalf("comment tags without CommentTag = %v:\n%s", err, generated)
+	}
+}
@@ -0,0 +1,226 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jiajia556/godo/internal/service"
+	"github.com/jiajia556/godo/internal/utils"
+)
+
+// ErrSchemaDrift is returned by DiffSchema when at least one table differs from
+// its model struct.
+var ErrSchemaDrift = errors.New("schema drift detected")
+
+// modelField is a struct field as declared in an existing model.go.
+type modelField struct {
+	typeName string
+	gormTag  string
+}
+
+// tableDrift lists the differences between a table and its model struct.
+type tableDrift struct {
+	tableName string
+	modelPath string
+	noModel   bool
+	missing   []string
+	extra     []string
+	mistyped  []string
+}
+
+func (d tableDrift) empty() bool {
+	return !d.noModel && len(d.missing) == 0 && len(d.extra) == 0 && len(d.mistyped) == 0
+}
+
+// DiffSchema compares the tables read from a SQL file or a database config file
+// with the structs in internal/common/models/<pkg>/model.go and writes a report
+// to w. Table names are mapped to models as gen model does, stripping prefix (or
+// the configured prefix when empty). A non-empty database selects the named
+// connection of the config file and the models generated for it with --db. It
+// returns ErrSchemaDrift when any table and model disagree.
+func DiffSchema(from, database, prefix string, w io.Writer) error {
+	if err := validateDatabaseName(database); err != nil {
+		return err
+	}
+	src := tableSource{Paths: []string{from}, Database: database}
+	definitions, err := extractCreateTables(src)
+	if err != nil {
+		return err
+	}
+	typeMap, err := service.GetModelTypeMap()
+	if err != nil {
+		return err
+	}
+	timestamps, err := service.GetModelTimestamps()
+	if err != nil {
+		return err
+	}
+	opts := StructOptions{Prefix: tablePrefix(src, prefix), TypeMap: typeMap, Timestamps: timestamps}
+
+	drifted := 0
+	for _, definition := range definitions {
+		opts.ReadOnly = definition.View
+		drift, err := diffTable(definition.CreateTable, database, opts)
+		if err != nil {
+			return err
+		}
+		if drift.empty() {
+			continue
+		}
+		drifted++
+		writeTableDrift(w, drift)
+	}
+	if drifted > 0 {
+		return fmt.Errorf("%w in %d of %d tables", ErrSchemaDrift, drifted, len(definitions))
+	}
+	_, err = fmt.Fprintf(w, "no drift detected in %d tables\n", len(definitions))
+	return err
+}
+
+func diffTable(createTable, database string, opts StructOptions) (tableDrift, error) {
+	table, err := parseSQL(createTable)
+	if err != nil {
+		return tableDrift{}, fmt.Errorf("parse CREATE TABLE statement: %w", err)
+	}
+	applyTypeMap(table, opts.TypeMap)
+	applyTimestampConventions(table.fields, opts.Timestamps)
+	if opts.ReadOnly {
+		markReadOnly(table.fields)
+	}
+	tableName, fields := table.name, table.fields
+	structName := modelStructName(tableName, opts.Prefix)
+	applyEnumTypes(structName, fields)
+	modelPath, err := modelFilePath(path.Join(database, modelPackageName(structName)), "model.go")
+	if err != nil {
+		return tableDrift{}, fmt.Errorf("table %s: %w", tableName, err)
+	}
+	return diffModelFile(tableName, structName, fields, modelPath)
+}
+
+// diffModelFile compares the parsed columns of tableName with the fields of
+// structName declared in the model file at path.
+func diffModelFile(tableName, structName string, fields []fieldInfo, path string) (tableDrift, error) {
+	drift := tableDrift{tableName: tableName, modelPath: path}
+	if !utils.IsFileExists(path) {
+		drift.noModel = true
+		return drift, nil
+	}
+
+	declared, err := readModelFields(path, structName)
+	if err != nil {
+		return tableDrift{}, err
+	}
+	expected := make(map[string]struct{}, len(fields))
+	for _, field := range fields {
+		expected[field.name] = struct{}{}
+		actual, ok := declared[field.name]
+		if !ok {
+			drift.missing = append(drift.missing, fmt.Sprintf("%s %s `gorm:%q`", field.name, field.typeName, field.gormTags))
+			continue
+		}
+		if actual.typeName != field.typeName {
+			drift.mistyped = append(drift.mistyped, fmt.Sprintf("%s: type %s in schema, %s in model", field.name, field.typeName, actual.typeName))
+		}
+		if !sameGormTags(actual.gormTag, field.gormTags) {
+			drift.mistyped = append(drift.mistyped, fmt.Sprintf("%s: gorm tag %q in schema, %q in model", field.name, field.gormTags, actual.gormTag))
+		}
+	}
+	for name, field := range declared {
+		if _, ok := expected[name]; !ok {
+			drift.extra = append(drift.extra, fmt.Sprintf("%s %s", name, field.typeName))
+		}
+	}
+	sort.Strings(drift.extra)
+	return drift, nil
+}
+
+// readModelFields returns the fields of structName declared in the Go file at
+// path, keyed by field name. Fields tagged gorm:"-" are not mapped to columns
+// and are ignored.
+func readModelFields(path, structName string) (map[string]modelField, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, fmt.Errorf("parse model file %s: %w", path, err)
+	}
+	structType, _ := findStructDecl(fset, file, structName)
+	if structType == nil {
+		return nil, fmt.Errorf("struct %s not found in %s", structName, path)
+	}
+
+	fields := make(map[string]modelField)
+	for _, field := range structType.Fields.List {
+		var gormTag string
+		if field.Tag != nil {
+			tag, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid struct tag %s in %s: %w", field.Tag.Value, path, err)
+			}
+			gormTag = reflect.StructTag(tag).Get("gorm")
+		}
+		// Ignored fields and associations have no column.
+		if gormTag == "-" || strings.Contains(gormTag, "foreignKey:") {
+			continue
+		}
+		info := modelField{typeName: types.ExprString(field.Type), gormTag: gormTag}
+		for _, name := range fieldNames(field) {
+			fields[name] = info
+		}
+	}
+	return fields, nil
+}
+
+func fieldNames(field *ast.Field) []string {
+	if len(field.Names) == 0 {
+		return []string{types.ExprString(field.Type)}
+	}
+	names := make([]string, 0, len(field.Names))
+	for _, name := range field.Names {
+		names = append(names, name.Name)
+	}
+	return names
+}
+
+// sameGormTags compares two gorm tag values ignoring the order of their parts.
+func sameGormTags(a, b string) bool {
+	return strings.Join(sortedGormTagParts(a), ";") == strings.Join(sortedGormTagParts(b), ";")
+}
+
+func sortedGormTagParts(tag string) []string {
+	var parts []string
+	for _, part := range strings.Split(tag, ";") {
+		// Comments are documentation and do not count as drift.
+		if part = strings.TrimSpace(part); part != "" && !strings.HasPrefix(part, "comment:") {
+			parts = append(parts, part)
+		}
+	}
+	sort.Strings(parts)
+	return parts
+}
+
+func writeTableDrift(w io.Writer, drift tableDrift) {
+	if drift.noModel {
+		fmt.Fprintf(w, "%s: model file not found: %s\n", drift.tableName, drift.modelPath)
+		return
+	}
+	fmt.Fprintf(w, "%s (%s):\n", drift.tableName, drift.modelPath)
+	for _, line := range drift.missing {
+		fmt.Fprintf(w, "  missing   %s\n", line)
+	}
+	for _, line := range drift.extra {
+		fmt.Fprintf(w, "  extra     %s\n", line)
+	}
+	for _, line := range drift.mistyped {
+		fmt.Fprintf(w, "  mistyped  %s\n", line)
+	}
+}
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffModelFileReportsMissingExtraAndMistypedFields(t *testing.T) {
+	ddl := "CREATE TABLE `users` (`id` bigint unsigned NOT NULL AUTO_INCREMENT, `name` varchar(64) NOT NULL, `email` varchar(128), `age` int, PRIMARY KEY (`id`));"
+	table, err := parseSQL(ddl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "model.go")
+	model := "package users\n\ntype Users struct {\n" +
+		"\tId     uint64 `gorm:\"primaryKey;column:id;unsigned;notNull;autoIncrement\" json:\"id\"`\n" +
+		"\tName   string `gorm:\"column:name\" json:\"name\"`\n" +
+		"\tAge    int64  `gorm:\"column:age\" json:\"age\"`\n" +
+		"\tLegacy bool   `gorm:\"column:legacy\" json:\"legacy\"`\n" +
+		"\tCache  string `gorm:\"-\" json:\"-\"`\n" +
+		"}\n"
+	if err := os.WriteFile(path, []byte(model), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	drift, err := diffModelFile(table.name, "Users", table.fields, path)
+	if err != nil {
+		t.Fatalf("diffModelFile() error = %v", err)
+	}
+	if drift.empty() {
+		t.Fatal("drift was not detected")
+	}
+	var report bytes.Buffer
+	writeTableDrift(&report, drift)
+	text := report.String()
+	for _, expected := range []string{
+		"missing   Email string",
+		"extra     Legacy bool",
+		"mistyped  Age: type int32 in schema, int64 in model",
+		`mistyped  Name: gorm tag "column:name;notNull" in schema, "column:name" in model`,
+	} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("report does not contain %q:\n%s", expected, text)
+		}
+	}
+	if strings.Contains(text, "Id:") || strings.Contains(text, "Cache") {
+		t.Fatalf("report contains fields without drift:\n%s", text)
+	}
+}
+
+func TestDiffModelFileReportsMissingModel(t *testing.T) {
+	drift, err := diffModelFile("users", "Users", nil, filepath.Join(t.TempDir(), "model.go"))
+	if err != nil || !drift.noModel || drift.empty() {
+		t.Fatalf("diffModelFile() = %+v, %v", drift, err)
+	}
+}
+
+func TestDiffSchemaReportsInputErrors(t *testing.T) {
+	var report bytes.Buffer
+	err := DiffSchema(filepath.Join(t.TempDir(), "missing.sql"), "", "", &report)
+	if err == nil || errors.Is(err, ErrSchemaDrift) || !strings.Contains(err.Error(), "read SQL file") {
+		t.Fatalf("DiffSchema() error = %v", err)
+	}
+	if err := DiffSchema("schema.sql", "Analytics", "", &report); err == nil || !strings.Contains(err.Error(), "invalid database name") {
+		t.Fatalf("DiffSchema(--db Analytics) error = %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/jiajia556/godo/internal/template"
+)
+
+var enumTypeRE = regexp.MustCompile(`(?i)^(enum|set)\s*\(`)
+
+// enumColumn is an ENUM or SET column and its allowed values.
+type enumColumn struct {
+	column string
+	set    bool
+	values []string
+}
+
+// parseEnumColumn returns the allowed values of an ENUM or SET column
+// definition, or nil for other column types.
+func parseEnumColumn(column, typeInfo string) (*enumColumn, error) {
+	matches := enumTypeRE.FindStringSubmatch(typeInfo)
+	if matches == nil {
+		return nil, nil
+	}
+	open := len(matches[0]) - 1
+	closing := matchingParen(typeInfo, open)
+	if closing < 0 {
+		return nil, fmt.Errorf("column %s: unterminated %s value list", column, matches[1])
+	}
+
+	enum := &enumColumn{column: column, set: strings.EqualFold(matches[1], "set")}
+	for _, item := range splitFieldDefinitions(typeInfo[open+1 : closing]) {
+		item = strings.TrimSpace(item)
+		if item == "" || (item[0] != '\'' && item[0] != '"') {
+			return nil, fmt.Errorf("column %s: invalid %s value %s", column, matches[1], item)
+		}
+		value, ok := unquoteSQLString(item)
+		if !ok {
+			return nil, fmt.Errorf("column %s: invalid %s value %s", column, matches[1], item)
+		}
+		enum.values = append(enum.values, value)
+	}
+	if len(enum.values) == 0 {
+		return nil, fmt.Errorf("column %s: %s without values", column, matches[1])
+	}
+	return enum, nil
+}
+
+// applyEnumTypes gives every ENUM and SET field of structName a named type:
+// <Struct><Field> for ENUM columns, and a <Struct><Field>Set slice of it for SET
+// columns. It returns the types to generate.
+func applyEnumTypes(structName string, fields []fieldInfo) []template.EnumData {
+	var enums []template.EnumData
+	for i, f := range fields {
+		if f.enum == nil {
+			continue
+		}
+		data := template.EnumData{
+			TypeName:   structName + f.name,
+			ColumnName: f.enum.column,
+		}
+		fields[i].typeName = data.TypeName
+		if f.enum.set {
+			data.SetTypeName = data.TypeName + "Set"
+			fields[i].typeName = data.SetTypeName
+		}
+
+		used := make(map[string]bool, len(f.enum.values))
+		for _, value := range f.enum.values {
+			name := data.TypeName + enumConstSuffix(value)
+			for n := 2; used[name]; n++ {
+				name = data.TypeName + enumConstSuffix(value) + strconv.Itoa(n)
+			}
+			used[name] = true
+			data.Values = append(data.Values, template.EnumValue{ConstName: name, Value: value})
+		}
+		data.EmptyIsNull = !f.enum.set && f.nullable && !slices.Contains(f.enum.values, "")
+		enums = append(enums, data)
+	}
+	return enums
+}
+
+// enumConstSuffix turns an enum value into the CamelCase suffix of its constant,
+// e.g. "in-progress" becomes "InProgress".
+func enumConstSuffix(value string) string {
+	words := strings.FieldsFunc(value, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		words[i] = capitalize(word)
+	}
+	if len(words) == 0 {
+		return "Empty"
+	}
+	return strings.Join(words, "")
+}
@@ -0,0 +1,86 @@
+package model
+
+import (
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jiajia556/godo/internal/template"
+	"github.com/jiajia556/godo/templates"
+)
+
+func TestParseEnumColumnReadsQuotedValues(t *testing.T) {
+	enum, err := parseEnumColumn("status", "ENUM('new','it''s done', \"a,b\") NOT NULL")
+	if err != nil {
+		t.Fatalf("parseEnumColumn() error = %v", err)
+	}
+	if enum.set || !reflect.DeepEqual(enum.values, []string{"new", "it's done", "a,b"}) {
+		t.Fatalf("enum = %+v", enum)
+	}
+	if enum, err := parseEnumColumn("name", "varchar(16) COMMENT 'enum(x)'"); enum != nil || err != nil {
+		t.Fatalf("non-enum column = %+v, %v", enum, err)
+	}
+	if _, err := parseEnumColumn("status", "enum(1, 2)"); err == nil {
+		t.Fatal("parseEnumColumn() accepted unquoted values")
+	}
+}
+
+func TestBuildModelGeneratesEnumAndSetTypes(t *testing.T) {
+	ddl := "CREATE TABLE `orders` (`id` bigint NOT NULL, `status` enum('pending','in-progress','') NOT NULL DEFAULT 'pending', `priority` enum('low','high'), `tags` set('a','b'), PRIMARY KEY (`id`));"
+	model, err := buildModel(ddl, StructOptions{})
+	if err != nil {
+		t.Fatalf("buildModel() error = %v", err)
+	}
+	if !strings.Contains(model.structText, "OrdersStatus ") || !strings.Contains(model.structText, "OrdersTagsSet ") {
+		t.Fatalf("enum fields keep plain types:\n%s", model.structText)
+	}
+	wantStatus := []template.EnumValue{
+		{ConstName: "OrdersStatusPending", Value: "pending"},
+		{ConstName: "OrdersStatusInProgress", Value: "in-progress"},
+		{ConstName: "OrdersStatusEmpty", Value: ""},
+	}
+	if len(model.enums) != 3 || !reflect.DeepEqual(model.enums[0].Values, wantStatus) || model.enums[0].SetTypeName != "" {
+		t.Fatalf("enums = %+v", model.enums)
+	}
+	if model.enums[0].EmptyIsNull || !model.enums[1].EmptyIsNull || model.enums[2].EmptyIsNull {
+		t.Fatalf("only the nullable enum without '' should store the empty value as NULL: %+v", model.enums)
+	}
+	if model.enums[2].TypeName != "OrdersTags" || model.enums[2].SetTypeName != "OrdersTagsSet" {
+		t.Fatalf("set enum = %+v", model.enums[2])
+	}
+
+	enumTemplate, err := templates.TemplateFS.ReadFile("default/internal/common/models/enum.go.templ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rendered, err := template.Render(string(enumTemplate), template.ModelData{
+		ModelPkg:   "orders",
+		TableName:  model.tableName,
+		Enums:      model.enums,
+		UseEnumSet: true,
+	})
+	if err != nil {
+		t.Fatalf("render enum template: %v", err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "enum.go", rendered, 0); err != nil {
+		t.Fatalf("enum.go does not parse: %v\n%s", err, rendered)
+	}
+	for _, want := range []string{
+		"func (v OrdersStatus) IsValid() bool",
+		"func (v *OrdersStatus) Scan(value any) error",
+		"func (v OrdersStatus) Value() (driver.Value, error) {\n\tif v == \"\" {\n\t\treturn string(v), nil",
+		"func (v OrdersPriority) Value() (driver.Value, error) {\n\tif v == \"\" {\n\t\treturn nil, nil",
+		"type OrdersTagsSet []OrdersTags",
+		"func (s *OrdersTagsSet) Scan(value any) error",
+		"func (s OrdersTagsSet) Value() (driver.Value, error)",
+	} {
+		if !strings.Contains(string(rendered), want) {
+			t.Errorf("enum.go does not contain %q", want)
+		}
+	}
+	if strings.Contains(string(rendered), "func (v *OrdersTags) Scan") {
+		t.Error("SET member type should not implement sql.Scanner")
+	}
+}
@@ -4,76 +4,424 @@ import (
 	"database/sql"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
 
-	_ "github.com/go-sql-driver/mysql"
 	"github.com/jiajia556/godo/internal/service"
 	"github.com/jiajia556/godo/internal/template"
 	"github.com/jiajia556/godo/internal/utils"
 	"github.com/jiajia556/godo/templates"
 )
 
-func genModel(from string) error {
-	var createTables []string
-	var err error
-	if strings.EqualFold(filepath.Ext(from), ".sql") {
-		createTables, err = extractCreateTablesFromSqlFile(from)
-	} else {
-		createTables, err = extractCreateTablesFromConfigFile(from)
+// generateOptions controls how model files are written.
+type generateOptions struct {
+	// Update rewrites the generated declarations of an existing model.go
+	// instead of skipping the file.
+	Update bool
+	// Associations is AssociationsBelongsTo, AssociationsHasMany or
+	// AssociationsNone.
+	Associations string
+	// Database names the connection the models belong to. Models of a named
+	// database are generated into internal/common/models/<Database>/<pkg> and
+	// use its sessions; the tables are read from databases.<Database> of the
+	// config file. Empty selects the default connection.
+	Database string
+	// DSN reads the tables from the MySQL database of a go-sql-driver/mysql
+	// data source name instead of a file.
+	DSN string
+	// Schema reads the tables from a schema other than the default one of the
+	// connection. It overrides mysql.schema of a config file.
+	Schema string
+	// Jobs bounds the tables introspected and generated at once; zero means
+	// one per CPU.
+	Jobs int
+	// Snapshot saves the tables read to a schema snapshot file, from which
+	// the models can later be generated without a database.
+	Snapshot string
+	// SaveJSONCase and SaveJSONOmitEmpty report that JSONCase and
+	// JSONOmitEmpty were given on the command line. Given values are saved to
+	// godoconfig.json; the others are read from it.
+	SaveJSONCase      bool
+	SaveJSONOmitEmpty bool
+	// StructOptions controls the generated struct. When Prefix is empty, the
+	// prefix of the config file is used; TypeMap and Timestamps are read from
+	// godoconfig.json.
+	StructOptions
+}
+
+func genModel(paths []string, opts generateOptions) error {
+	if err := validateDatabaseName(opts.Database); err != nil {
+		return err
 	}
+	src := tableSource{Paths: paths, DSN: opts.DSN, Database: opts.Database, Schema: opts.Schema, Jobs: opts.Jobs}
+	definitions, err := extractCreateTables(src)
 	if err != nil {
-		return fmt.Errorf("extract CREATE TABLE statements from %s: %w", from, err)
+		return err
 	}
-	if len(createTables) == 0 {
-		return fmt.Errorf("no CREATE TABLE statements found in %s", from)
+	opts.Prefix = tablePrefix(src, opts.Prefix)
+	if opts.Snapshot != "" {
+		// Generate from the snapshot's form of the tables so that generating
+		// from the snapshot file later gives the same models.
+		if definitions, err = writeSchemaSnapshot(opts.Snapshot, definitions, opts.Prefix); err != nil {
+			return err
+		}
+		utils.OutputInfof("wrote schema snapshot of %d tables to %s", len(definitions), opts.Snapshot)
+	}
+	if opts.TypeMap, err = service.GetModelTypeMap(); err != nil {
+		return err
+	}
+	if err := resolveJSONOptions(&opts); err != nil {
+		return err
+	}
+	if opts.Timestamps, err = service.GetModelTimestamps(); err != nil {
+		return err
 	}
 
-	recordContent, err := templates.TemplateFS.ReadFile("default/internal/common/models/record.go.templ")
+	projectName, err := service.GetProjectName()
 	if err != nil {
-		return fmt.Errorf("read record template: %w", err)
+		return fmt.Errorf("get project name: %w", err)
 	}
-	listContent, err := templates.TemplateFS.ReadFile("default/internal/common/models/list.go.templ")
-	if err != nil {
-		return fmt.Errorf("read list template: %w", err)
+	modelsPath := path.Join(projectName, "internal/common/models", opts.Database)
+	if opts.associations, err = planAssociations(tableStatements(definitions), opts.Associations, opts.Prefix, modelsPath); err != nil {
+		return err
 	}
-	modelContent, err := templates.TemplateFS.ReadFile("default/internal/common/models/model.go.templ")
+
+	tmpls, err := loadModelTemplates()
 	if err != nil {
-		return fmt.Errorf("read model template: %w", err)
+		return err
+	}
+
+	// Tables of the same model package are generated by one worker, in order,
+	// so that the first one wins as when generating sequentially.
+	groups := groupByModelDir(definitions, opts)
+	files := make([][]string, len(definitions))
+	summaries := make([]string, len(definitions))
+	progress := newProgress("generate models", len(definitions))
+	err = runParallel(len(groups), opts.Jobs, nil, func(g int) error {
+		for _, i := range groups[g] {
+			var err error
+			if files[i], summaries[i], err = generateModelFromSQL(definitions[i], tmpls, opts); err != nil {
+				return err
+			}
+			progress.done()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
 	var generatedFiles []string
-	for _, createTable := range createTables {
-		files, err := generateModelFromSQL(createTable, string(recordContent), string(listContent), string(modelContent))
+	for i := range definitions {
+		if summaries[i] != "" {
+			utils.OutputInfof("%s", summaries[i])
+		}
+		generatedFiles = append(generatedFiles, files[i]...)
+	}
+	if err := runPostGenerationTasks(generatedFiles); err != nil {
+		return err
+	}
+	return saveJSONOptions(opts)
+}
+
+// groupByModelDir groups the indexes of definitions by the directory their
+// model is generated to, in the order of their first table. Statements whose
+// table name cannot be read are left alone and fail when generated.
+func groupByModelDir(definitions []tableDefinition, opts generateOptions) [][]int {
+	var groups [][]int
+	byDir := make(map[string]int, len(definitions))
+	for i, definition := range definitions {
+		tableName, err := extractTableName(definition.CreateTable)
 		if err != nil {
-			return err
+			groups = append(groups, []int{i})
+			continue
+		}
+		dir := path.Join(opts.Database, modelPackageName(modelStructName(tableName, opts.Prefix)))
+		if g, ok := byDir[dir]; ok {
+			groups[g] = append(groups[g], i)
+			continue
 		}
-		generatedFiles = append(generatedFiles, files...)
+		byDir[dir] = len(groups)
+		groups = append(groups, []int{i})
 	}
-	return runPostGenerationTasks(generatedFiles)
+	return groups
 }
 
-func extractCreateTablesFromSqlFile(filePath string) ([]string, error) {
-	content, err := os.ReadFile(filePath)
+// resolveJSONOptions fills the JSON tag options not given on the command line
+// from godoconfig.json.
+func resolveJSONOptions(opts *generateOptions) error {
+	jsonCase, omitEmpty, err := service.GetModelJSONOptions()
 	if err != nil {
-		return nil, fmt.Errorf("read SQL file: %w", err)
+		return err
+	}
+	if !opts.SaveJSONCase {
+		opts.JSONCase = jsonCase
+	}
+	if !opts.SaveJSONOmitEmpty {
+		opts.JSONOmitEmpty = omitEmpty
 	}
+	if opts.SaveJSONCase {
+		if opts.JSONCase, err = service.NormalizeJSONCase(opts.JSONCase); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// saveJSONOptions saves the JSON tag options given on the command line to
+// godoconfig.json once the models have been generated with them.
+func saveJSONOptions(opts generateOptions) error {
+	if !opts.SaveJSONCase && !opts.SaveJSONOmitEmpty {
+		return nil
+	}
+	if err := service.SetModelJSONOptions(opts.JSONCase, opts.JSONOmitEmpty); err != nil {
+		return fmt.Errorf("save JSON tag options: %w", err)
+	}
+	return nil
+}
+
+var databaseNameRE = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// modelTemplates holds the templates a model package is generated from.
+type modelTemplates struct {
+	record     string
+	list       string
+	repository string
+	model      string
+	enum       string
+}
 
-	statements, err := splitSQLStatements(string(content))
+func loadModelTemplates() (modelTemplates, error) {
+	var tmpls modelTemplates
+	for _, t := range []struct {
+		name   string
+		target *string
+	}{
+		{"record", &tmpls.record},
+		{"list", &tmpls.list},
+		{"repository", &tmpls.repository},
+		{"model", &tmpls.model},
+		{"enum", &tmpls.enum},
+	} {
+		content, err := templates.TemplateFS.ReadFile("default/internal/common/models/" + t.name + ".go.templ")
+		if err != nil {
+			return modelTemplates{}, fmt.Errorf("read %s template: %w", t.name, err)
+		}
+		*t.target = string(content)
+	}
+	return tmpls, nil
+}
+
+// validateDatabaseName checks a --db name, which becomes a directory and is
+// looked up under databases in config files.
+func validateDatabaseName(name string) error {
+	if name != "" && !databaseNameRE.MatchString(name) {
+		return fmt.Errorf("invalid database name %q: use lowercase letters, digits and underscores, starting with a letter", name)
+	}
+	return nil
+}
+
+// dsnEnv is the environment variable gen model reads a DSN from when given
+// neither a file nor --dsn, which keeps the password out of the command line.
+const dsnEnv = "GODO_DSN"
+
+// tableSource locates the CREATE TABLE statements to read: SQL files and
+// directories of them, a schema snapshot, a config file, or the MySQL database
+// of a DSN.
+type tableSource struct {
+	Paths []string
+	DSN   string
+	// Database selects the connection of a config file.
+	Database string
+	// Schema overrides the schema the tables are read from.
+	Schema string
+	// Jobs bounds the tables introspected at once.
+	Jobs int
+}
+
+// String describes the source for messages, without the DSN password.
+func (s tableSource) String() string {
+	if s.DSN != "" {
+		return service.RedactDSN(s.DSN)
+	}
+	return strings.Join(s.Paths, ", ")
+}
+
+// isSnapshot reports whether the only path is a schema snapshot file.
+func (s tableSource) isSnapshot() bool {
+	return len(s.Paths) == 1 && isSchemaSnapshot(s.Paths[0])
+}
+
+// isSQL reports whether the paths are SQL files and directories of them.
+func (s tableSource) isSQL() bool {
+	if len(s.Paths) == 0 {
+		return false
+	}
+	for _, path := range s.Paths {
+		if info, err := os.Stat(path); (err != nil || !info.IsDir()) && !isSQLFile(path) {
+			return false
+		}
+	}
+	return true
+}
+
+// modelSource returns the file and directory arguments of gen model, the config
+// file of --from-db, or the DSN given with --dsn or in $GODO_DSN when there are
+// none.
+func modelSource(args []string, dsn, fromDB string) (paths []string, sourceDSN string, err error) {
+	if fromDB != "" {
+		if len(args) > 0 || dsn != "" {
+			return nil, "", fmt.Errorf("pass either --from-db, files or --dsn, not more than one")
+		}
+		if src := (tableSource{Paths: []string{fromDB}}); src.isSQL() || src.isSnapshot() {
+			return nil, "", fmt.Errorf("--from-db takes a config file, not %s", fromDB)
+		}
+		return []string{fromDB}, "", nil
+	}
+	if len(args) > 0 {
+		if dsn != "" {
+			return nil, "", fmt.Errorf("pass either SQL or config files or --dsn, not both")
+		}
+		return args, "", nil
+	}
+	if dsn == "" {
+		dsn = os.Getenv(dsnEnv)
+	}
+	if dsn == "" {
+		return nil, "", fmt.Errorf("pass a SQL or config file, --dsn or set %s", dsnEnv)
+	}
+	return nil, dsn, nil
+}
+
+// tableDefinition is a table or view read by gen model. Views are described by
+// a CREATE TABLE statement of their columns.
+type tableDefinition struct {
+	CreateTable string
+	View        bool
+}
+
+// tableStatements returns the CREATE TABLE statements of the tables among
+// definitions, leaving out views.
+func tableStatements(definitions []tableDefinition) []string {
+	statements := make([]string, 0, len(definitions))
+	for _, definition := range definitions {
+		if !definition.View {
+			statements = append(statements, definition.CreateTable)
+		}
+	}
+	return statements
+}
+
+// extractCreateTables reads the tables and views of src and fails when there
+// are none.
+func extractCreateTables(src tableSource) ([]tableDefinition, error) {
+	createTables, err := readCreateTables(src)
 	if err != nil {
-		return nil, fmt.Errorf("parse SQL file: %w", err)
+		return nil, err
+	}
+	if len(createTables) == 0 {
+		return nil, fmt.Errorf("no CREATE TABLE statements found in %s", src)
 	}
-	createTables := make([]string, 0, len(statements))
-	for _, statement := range statements {
-		if createTableHeaderRE.MatchString(statement) {
-			createTables = append(createTables, strings.TrimSpace(statement)+";")
+	return createTables, nil
+}
+
+// tablePrefix returns prefix, or when it is empty the prefix saved in the
+// schema snapshot or configured for the database in the config file of src. It
+// must be called after the tables of src have been read.
+func tablePrefix(src tableSource, prefix string) string {
+	if prefix != "" || src.DSN != "" || src.isSQL() {
+		return prefix
+	}
+	if src.isSnapshot() {
+		snapshot, _ := readSchemaSnapshot(src.Paths[0])
+		if snapshot == nil {
+			return ""
 		}
+		return snapshot.Prefix
 	}
+	mysql, _ := service.GetConfig().MysqlFor(src.Database)
+	return mysql.Prefix
+}
 
+func isSQLFile(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".sql")
+}
+
+func readCreateTables(src tableSource) ([]tableDefinition, error) {
+	var createTables []tableDefinition
+	var err error
+	switch {
+	case src.DSN != "":
+		createTables, err = extractCreateTablesFromDSN(src.DSN, src.Schema, src.Jobs)
+	case src.isSQL():
+		createTables, err = extractCreateTablesFromSqlFile(src.Paths...)
+	case src.isSnapshot():
+		var snapshot *schemaSnapshot
+		if snapshot, err = readSchemaSnapshot(src.Paths[0]); err == nil {
+			createTables = snapshot.definitions()
+		}
+	case len(src.Paths) != 1:
+		err = fmt.Errorf("a config file must be the only argument")
+	default:
+		createTables, err = extractCreateTablesFromConfigFile(src.Paths[0], src.Database, src.Schema, src.Jobs)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("extract CREATE TABLE statements from %s: %w", src, err)
+	}
 	return createTables, nil
 }
 
-func splitSQLStatements(content string) ([]string, error) {
+// LoadTableSchemas parses every CREATE TABLE statement of a SQL file or
+// directory, a schema snapshot, or of the database described by a config file. Views are left
+// out. An input without tables yields none.
+func LoadTableSchemas(from string) ([]*TableSchema, error) {
+	definitions, err := readCreateTables(tableSource{Paths: []string{from}})
+	if err != nil {
+		return nil, err
+	}
+	createTables := tableStatements(definitions)
+	tables := make([]*TableSchema, 0, len(createTables))
+	for _, createTable := range createTables {
+		table, err := ParseCreateTable(createTable)
+		if err != nil {
+			return nil, fmt.Errorf("parse CREATE TABLE statement in %s: %w", from, err)
+		}
+		tables = append(tables, table)
+	}
+	return tables, nil
+}
+
+// extractCreateTablesFromSqlFile replays the DDL statements of SQL files and
+// directories, in order, and returns the resulting tables and views.
+func extractCreateTablesFromSqlFile(paths ...string) ([]tableDefinition, error) {
+	files, err := sqlFiles(paths)
+	if err != nil {
+		return nil, err
+	}
+	var replay schemaReplay
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read SQL file: %w", err)
+		}
+		statements, err := SplitSQLStatements(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("parse SQL file %s: %w", file, err)
+		}
+		for _, statement := range statements {
+			if err := replay.apply(statement); err != nil {
+				return nil, fmt.Errorf("apply %s: %w", file, err)
+			}
+		}
+	}
+	return replay.definitions(), nil
+}
+
+// SplitSQLStatements splits SQL script content into statements on top-level
+// semicolons. Comments are dropped and quoted text is kept intact.
+func SplitSQLStatements(content string) ([]string, error) {
 	var statements []string
 	var current strings.Builder
 	var quote byte
@@ -162,142 +510,320 @@ func splitSQLStatements(content string) ([]string, error) {
 	return statements, nil
 }
 
-func extractCreateTablesFromConfigFile(filePath string) ([]string, error) {
+// extractCreateTablesFromConfigFile reads the tables of the named database of a
+// config file, from schema or else from its mysql schema setting.
+func extractCreateTablesFromConfigFile(filePath, database, schema string, jobs int) ([]tableDefinition, error) {
 	err := service.LoadConfig(filePath)
 	if err != nil {
 		return nil, err
 	}
-	conf := service.GetConfig()
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		conf.Mysql.User,
-		conf.Mysql.Password,
-		conf.Mysql.Host,
-		conf.Mysql.Port,
-		conf.Mysql.DBName,
-	)
+	mysql, err := service.GetConfig().MysqlFor(database)
+	if err != nil {
+		return nil, err
+	}
+	dsn, err := mysql.DSN()
+	if err != nil {
+		return nil, fmt.Errorf("build mysql DSN: %w", err)
+	}
+	if schema == "" {
+		schema = mysql.Schema
+	}
+	return extractCreateTablesFromDSN(dsn, schema, jobs)
+}
 
-	db, err := sql.Open("mysql", dsn)
+// extractCreateTablesFromDSN reads the tables and views of schema, or of the
+// default schema of the connection when empty, on up to jobs connections.
+// Views are described by their columns as information_schema reports them.
+func extractCreateTablesFromDSN(dsn, schema string, jobs int) ([]tableDefinition, error) {
+	db, err := service.OpenMysql(dsn)
 	if err != nil {
-		return nil, fmt.Errorf("open database: %w", err)
+		return nil, err
 	}
 	defer db.Close()
+	jobs = workerCount(jobs)
+	db.SetMaxOpenConns(jobs)
+	db.SetMaxIdleConns(jobs)
 
-	if err = db.Ping(); err != nil {
-		return nil, fmt.Errorf("ping database: %w", err)
+	showTables := "SHOW FULL TABLES"
+	if schema != "" {
+		showTables += " FROM " + QuoteIdentifier(schema)
 	}
-
-	rows, err := db.Query("SHOW TABLES")
+	rows, err := db.Query(showTables)
 	if err != nil {
-		return nil, fmt.Errorf("execute SHOW TABLES: %w", err)
+		return nil, fmt.Errorf("execute %s: %w", showTables, err)
 	}
 	defer rows.Close()
 
-	var tables []string
+	var tables []tableDefinition
 	for rows.Next() {
-		var tableName string
-		if err = rows.Scan(&tableName); err != nil {
+		var tableName, tableType string
+		if err = rows.Scan(&tableName, &tableType); err != nil {
 			return nil, fmt.Errorf("scan table name: %w", err)
 		}
-		tables = append(tables, tableName)
+		tables = append(tables, tableDefinition{CreateTable: tableName, View: tableType == "VIEW"})
 	}
 
 	if err = rows.Err(); err != nil {
 		return nil, fmt.Errorf("iterate over tables: %w", err)
 	}
 
-	var createTables []string
-	for _, table := range tables {
-		query := fmt.Sprintf("SHOW CREATE TABLE `%s`", table)
+	err = runParallel(len(tables), jobs, newProgress("introspect tables", len(tables)), func(i int) error {
+		table := tables[i]
+		if table.View {
+			var err error
+			tables[i].CreateTable, err = viewCreateTable(db, schema, table.CreateTable)
+			return err
+		}
+		name := QuoteIdentifier(table.CreateTable)
+		if schema != "" {
+			name = QuoteIdentifier(schema) + "." + name
+		}
 		var tableName, createStmt string
-		err = db.QueryRow(query).Scan(&tableName, &createStmt)
-		if err != nil {
-			return nil, fmt.Errorf("get CREATE TABLE statement for %s: %w", table, err)
+		if err := db.QueryRow("SHOW CREATE TABLE "+name).Scan(&tableName, &createStmt); err != nil {
+			return fmt.Errorf("get CREATE TABLE statement for %s: %w", table.CreateTable, err)
 		}
-		createStmt += ";"
-		createTables = append(createTables, createStmt)
+		tables[i].CreateTable = createStmt + ";"
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	return createTables, nil
+	return tables, nil
 }
 
-func generateModelFromSQL(sql, recordTmpl, listTmpl, modelTmpl string) ([]string, error) {
-	// Generate model structure from SQL
-	structText, structName, tableName, err := GenerateModelStruct(sql)
+// viewCreateTable returns a CREATE TABLE statement of the columns of a view.
+func viewCreateTable(db *sql.DB, schema, view string) (string, error) {
+	var schemaArg any
+	if schema != "" {
+		schemaArg = schema
+	}
+	rows, err := db.Query("SELECT COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE, COLUMN_COMMENT FROM information_schema.COLUMNS "+
+		"WHERE TABLE_SCHEMA = COALESCE(?, DATABASE()) AND TABLE_NAME = ? ORDER BY ORDINAL_POSITION", schemaArg, view)
 	if err != nil {
-		return nil, fmt.Errorf("generate model struct: %w", err)
+		return "", fmt.Errorf("get columns of view %s: %w", view, err)
 	}
+	defer rows.Close()
 
-	// Prepare model package name
-	modelPkg := strings.ToLower(structName)
+	table := &TableSchema{Name: view}
+	for rows.Next() {
+		var name, columnType, nullable, comment string
+		if err = rows.Scan(&name, &columnType, &nullable, &comment); err != nil {
+			return "", fmt.Errorf("scan column of view %s: %w", view, err)
+		}
+		table.Columns = append(table.Columns, ColumnSchema{Name: name, Definition: viewColumnType(columnType, nullable == "NO", comment)})
+	}
+	if err = rows.Err(); err != nil {
+		return "", fmt.Errorf("iterate over columns of view %s: %w", view, err)
+	}
+	return table.CreateStatement(), nil
+}
+
+// generateModelFromSQL writes the missing files of the model package of a table
+// or view. It returns the files written and, with --update, the summary of the
+// changes to model.go.
+func generateModelFromSQL(definition tableDefinition, tmpls modelTemplates, opts generateOptions) ([]string, string, error) {
+	// Generate model structure from SQL
+	opts.ReadOnly = definition.View
+	model, err := buildModel(definition.CreateTable, opts.StructOptions)
+	if err != nil {
+		return nil, "", fmt.Errorf("generate model struct: %w", err)
+	}
+	data, err := newModelData(model, definition.CreateTable)
+	if err != nil {
+		return nil, "", err
+	}
+	data.Database = opts.Database
+	data.View = definition.View
 
 	// Generate record file
-	generatedFiles := make([]string, 0, 3)
-	if path, err := generateModelFile(modelPkg, tableName, structName, structText, sql, recordTmpl, "record.go"); err != nil {
-		return nil, err
+	generatedFiles := make([]string, 0, 5)
+	if path, err := generateModelFile(data, tmpls.record, "record.go"); err != nil {
+		return nil, "", err
 	} else if path != "" {
 		generatedFiles = append(generatedFiles, path)
 	}
 
 	// Generate list file
-	if path, err := generateModelFile(modelPkg, tableName, structName, structText, sql, listTmpl, "list.go"); err != nil {
-		return nil, err
+	if path, err := generateModelFile(data, tmpls.list, "list.go"); err != nil {
+		return nil, "", err
 	} else if path != "" {
 		generatedFiles = append(generatedFiles, path)
 	}
 
-	// Generate model file
-	if path, err := generateModelFile(modelPkg, tableName, structName, structText, sql, modelTmpl, "model.go"); err != nil {
-		return nil, err
+	// Generate repository file; views are read-only and have none
+	if !data.View {
+		if path, err := generateModelFile(data, tmpls.repository, "repository.go"); err != nil {
+			return nil, "", err
+		} else if path != "" {
+			generatedFiles = append(generatedFiles, path)
+		}
+	}
+
+	// Generate enum types; the file is fully generated and always rewritten
+	if path, err := generateEnumFile(data, tmpls.enum); err != nil {
+		return nil, "", err
 	} else if path != "" {
 		generatedFiles = append(generatedFiles, path)
 	}
-	return generatedFiles, nil
+
+	// Generate model file, or refresh its generated declarations
+	if opts.Update {
+		path, summary, err := updateExistingModel(data, tmpls.model)
+		if err != nil {
+			return nil, "", err
+		}
+		if path != "" {
+			generatedFiles = append(generatedFiles, path)
+			return generatedFiles, summary, nil
+		}
+	}
+	if path, err := generateModelFile(data, tmpls.model, "model.go"); err != nil {
+		return nil, "", err
+	} else if path != "" {
+		generatedFiles = append(generatedFiles, path)
+	}
+	return generatedFiles, "", nil
 }
 
-func generateModelFile(modelPkg, tableName, structName, structText, createDDL, templateContent, fileName string) (string, error) {
-	// Set up file paths
-	var err error
-	path := filepath.Join("internal/common/models", modelPkg, fileName)
-	path, err = service.GetAbsPath(path)
+// modelPackageName returns the package directory under internal/common/models
+// that holds the model for structName.
+func modelPackageName(structName string) string {
+	return strings.ToLower(structName)
+}
+
+// modelDir returns the directory of the model package under
+// internal/common/models.
+func modelDir(data template.ModelData) string {
+	return path.Join(data.Database, data.ModelPkg)
+}
+
+// modelFilePath returns the absolute path of fileName in the package modelPkg.
+func modelFilePath(modelPkg, fileName string) (string, error) {
+	path, err := service.GetAbsPath(filepath.Join("internal/common/models", modelPkg, fileName))
 	if err != nil {
 		return "", fmt.Errorf("resolve model file %s: %w", fileName, err)
 	}
+	return path, nil
+}
+
+func generateModelFile(data template.ModelData, templateContent, fileName string) (string, error) {
+	// Set up file paths
+	path, err := modelFilePath(modelDir(data), fileName)
+	if err != nil {
+		return "", err
+	}
 
 	// Skip if file already exists
 	if utils.IsFileExists(path) {
 		return "", nil
 	}
 
-	// Prepare template data
+	// Create directory structure
+	dir := filepath.Dir(path)
+	if err = os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("create model directory %s: %w", dir, err)
+	}
+
+	// Generate file from template
+	if err = template.CreateFile(templateContent, data, path); err != nil {
+		return "", fmt.Errorf("write model file %s: %w", fileName, err)
+	}
+	return path, nil
+}
+
+// generateEnumFile writes enum.go with the types of the ENUM and SET columns,
+// replacing any previous version. A stale enum.go is removed when the table no
+// longer has such columns.
+func generateEnumFile(data template.ModelData, templateContent string) (string, error) {
+	path, err := modelFilePath(modelDir(data), "enum.go")
+	if err != nil {
+		return "", err
+	}
+	if len(data.Enums) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("remove stale enum file: %w", err)
+		}
+		return "", nil
+	}
+	if err := template.CreateFile(templateContent, data, path); err != nil {
+		return "", fmt.Errorf("write model file enum.go: %w", err)
+	}
+	return path, nil
+}
+
+// updateExistingModel refreshes the generated declarations of an existing model.go
+// and returns its path and a summary of the field changes. The path is empty when
+// model.go does not exist yet, in which case the caller generates it from scratch.
+func updateExistingModel(data template.ModelData, modelTmpl string) (string, string, error) {
+	path, err := modelFilePath(modelDir(data), "model.go")
+	if err != nil {
+		return "", "", err
+	}
+	if !utils.IsFileExists(path) {
+		return "", "", nil
+	}
+	changes, err := updateModelFile(path, data, modelTmpl)
+	if err != nil {
+		return "", "", fmt.Errorf("update model %s: %w", data.TableName, err)
+	}
+	return path, fmt.Sprintf("%s: %s", data.TableName, changes), nil
+}
+
+func newModelData(model *generatedModel, createDDL string) (template.ModelData, error) {
 	projectName, err := service.GetProjectName()
 	if err != nil {
-		return "", fmt.Errorf("get project name: %w", err)
+		return template.ModelData{}, fmt.Errorf("get project name: %w", err)
 	}
+	return modelTemplateData(model, projectName, createDDL), nil
+}
 
+// modelTemplateData builds the data the model templates are rendered with.
+func modelTemplateData(model *generatedModel, projectName, createDDL string) template.ModelData {
 	createDDL = strings.ReplaceAll(createDDL, "\r\n", " ")
 	createDDL = strings.ReplaceAll(createDDL, "\n", " ")
 
-	data := template.ModelData{
-		ModelPkg:        modelPkg,
+	useEnumSet := false
+	for _, enum := range model.enums {
+		useEnumSet = useEnumSet || enum.SetTypeName != ""
+	}
+	return template.ModelData{
+		ModelPkg:        modelPackageName(model.structName),
 		ProjectName:     projectName,
-		ModelStruct:     structText,
-		ModelStructName: structName,
-		TableName:       tableName,
+		ModelStruct:     model.structText,
+		ModelStructName: model.structName,
+		TableName:       model.tableName,
 		CreateDDL:       createDDL,
-		UseTime:         strings.Contains(structText, "time.Time"),
-		UseDecimal:      strings.Contains(structText, "decimal.Decimal"),
+		Imports:         model.imports,
+		Enums:           model.enums,
+		UseEnumSet:      useEnumSet,
+		IDExpr:          idExpression(model.primaryKey),
+		PrimaryKeys:     model.primaryKey,
+		KeyTypeName:     keyTypeName(model.structName, model.primaryKey),
 	}
+}
 
-	// Create directory structure
-	dir := filepath.Dir(path)
-	if err = os.MkdirAll(dir, 0o755); err != nil {
-		return "", fmt.Errorf("create model directory %s: %w", dir, err)
+// idExpression returns what ID() returns for a primary key. Only a single
+// integer column can be represented as the uint64 required by mysqlx.Model.
+func idExpression(keys []template.KeyField) string {
+	if len(keys) != 1 {
+		return "0"
 	}
+	switch keys[0].Type {
+	case "uint64":
+		return "data." + keys[0].Field
+	case "int8", "int16", "int32", "int64", "int", "uint8", "uint16", "uint32", "uint":
+		return "uint64(data." + keys[0].Field + ")"
+	}
+	return "0"
+}
 
-	// Generate file from template
-	if err = template.CreateFile(templateContent, data, path); err != nil {
-		return "", fmt.Errorf("write model file %s: %w", fileName, err)
+// keyTypeName returns the name of the struct generated for a composite primary
+// key, or "" for other keys.
+func keyTypeName(structName string, keys []template.KeyField) string {
+	if len(keys) < 2 {
+		return ""
 	}
-	return path, nil
+	return structName + "Key"
 }
 
 func runPostGenerationTasks(generatedFiles []string) error {
@@ -1,14 +1,17 @@
 package model
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/jiajia556/godo/internal/template"
 )
 
 func TestGenerateModelFromSQLReturnsParseError(t *testing.T) {
-	_, err := generateModelFromSQL("not a CREATE TABLE statement", "", "", "")
+	_, _, err := generateModelFromSQL(tableDefinition{CreateTable: "not a CREATE TABLE statement"}, modelTemplates{}, generateOptions{})
 	if err == nil {
 		t.Fatal("generateModelFromSQL() succeeded for invalid SQL")
 	}
@@ -23,13 +26,15 @@ func TestGenerateModelFromSQLCreatesFilesAndSkipsExisting(t *testing.T) {
 	sql := "CREATE TABLE `users` (`id` bigint NOT NULL, `name` varchar(64) NOT NULL, PRIMARY KEY (`id`));"
 	recordTemplate := "package {{.ModelPkg}}\n\n{{.ModelStruct}}\n"
 	listTemplate := "package {{.ModelPkg}}\n\ntype {{.ModelStructName}}List []{{.ModelStructName}}\n"
+	repositoryTemplate := "package {{.ModelPkg}}\n\ntype Repository interface{ Get(key ...any) (*{{.ModelStructName}}, error) }\n"
 	modelTemplate := "package {{.ModelPkg}}\n\nconst TableName = {{printf \"%q\" .TableName}}\n"
+	tmpls := modelTemplates{record: recordTemplate, list: listTemplate, repository: repositoryTemplate, model: modelTemplate}
 
-	files, err := generateModelFromSQL(sql, recordTemplate, listTemplate, modelTemplate)
+	files, _, err := generateModelFromSQL(tableDefinition{CreateTable: sql}, tmpls, generateOptions{})
 	if err != nil {
 		t.Fatalf("generateModelFromSQL() error = %v", err)
 	}
-	if len(files) != 3 {
+	if len(files) != 4 {
 		t.Fatalf("generated files = %v", files)
 	}
 	for _, file := range files {
@@ -46,10 +51,125 @@ func TestGenerateModelFromSQLCreatesFilesAndSkipsExisting(t *testing.T) {
 		t.Fatalf("record content = %s, err = %v", record, err)
 	}
 
-	files, err = generateModelFromSQL(sql, recordTemplate, listTemplate, modelTemplate)
+	files, _, err = generateModelFromSQL(tableDefinition{CreateTable: sql}, tmpls, generateOptions{})
 	if err != nil || len(files) != 0 {
 		t.Fatalf("second generation = %v, %v", files, err)
 	}
+
+	files, _, err = generateModelFromSQL(tableDefinition{CreateTable: sql}, tmpls, generateOptions{Database: "analytics"})
+	if err != nil || len(files) != 4 || filepath.Dir(files[0]) != filepath.Join(root, "internal", "common", "models", "analytics", "users") {
+		t.Fatalf("generation for a named database = %v, %v", files, err)
+	}
+
+	// Views get read-only fields, a ViewRecord and no repository.
+	if tmpls, err = loadModelTemplates(); err != nil {
+		t.Fatal(err)
+	}
+	view := tableDefinition{CreateTable: "CREATE TABLE `user_totals` (`user_id` bigint NOT NULL, `total` decimal(65,30));", View: true}
+	files, _, err = generateModelFromSQL(view, tmpls, generateOptions{})
+	if err != nil {
+		t.Fatalf("generateModelFromSQL(view) error = %v", err)
+	}
+	var names []string
+	for _, file := range files {
+		names = append(names, filepath.Base(file))
+	}
+	if strings.Join(names, ",") != "record.go,list.go,model.go" {
+		t.Fatalf("generated files = %v", names)
+	}
+	dir := filepath.Join(root, "internal", "common", "models", "usertotals")
+	model, _ := os.ReadFile(filepath.Join(dir, "model.go"))
+	record, _ = os.ReadFile(filepath.Join(dir, "record.go"))
+	list, _ := os.ReadFile(filepath.Join(dir, "list.go"))
+	for _, snippet := range []string{`gorm:"column:user_id;->;notNull"`, `gorm:"column:total;->"`, "func (data *UserTotals) IsView() bool"} {
+		if !strings.Contains(string(model), snippet) {
+			t.Fatalf("model.go does not contain %q:\n%s", snippet, model)
+		}
+	}
+	if !strings.Contains(string(record), "*models.ViewRecord[*UserTotals]") || strings.Contains(string(record), "BaseRecord") ||
+		strings.Contains(string(record)+string(list), "CreateTableIfNotExists") {
+		t.Fatalf("view record.go = %s\nlist.go = %s", record, list)
+	}
+
+	// model diff --db compares the models generated for that database.
+	events := "CREATE TABLE `events` (`id` bigint NOT NULL, `name` varchar(64) NOT NULL, PRIMARY KEY (`id`));"
+	if _, _, err = generateModelFromSQL(tableDefinition{CreateTable: events}, tmpls, generateOptions{Database: "analytics"}); err != nil {
+		t.Fatalf("generateModelFromSQL(events) error = %v", err)
+	}
+	eventsSQL := filepath.Join(root, "events.sql")
+	if err := os.WriteFile(eventsSQL, []byte(events), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var report strings.Builder
+	if err := DiffSchema(eventsSQL, "analytics", "", &report); err != nil || report.String() != "no drift detected in 1 tables\n" {
+		t.Fatalf("DiffSchema(--db analytics) = %q, %v", report.String(), err)
+	}
+	report.Reset()
+	if err := DiffSchema(eventsSQL, "", "", &report); !errors.Is(err, ErrSchemaDrift) || !strings.Contains(report.String(), "model file not found") {
+		t.Fatalf("DiffSchema() = %q, %v", report.String(), err)
+	}
+
+	info, err := LoadModelInfo("usertotals")
+	if err != nil || !info.View || !info.Fields[0].Managed {
+		t.Fatalf("LoadModelInfo(view) = %+v, %v", info, err)
+	}
+
+	// JSON tag options given on the command line are only saved once the
+	// models were generated.
+	schema := filepath.Join(root, "orders.sql")
+	if err := os.WriteFile(schema, []byte("CREATE TABLE `orders` (`id` bigint NOT NULL, PRIMARY KEY (`id`));"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "internal", "common", "models", "orders"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	opts := generateOptions{SaveJSONCase: true, StructOptions: StructOptions{JSONCase: "camel"}}
+	if err := genModel([]string{schema}, opts); err == nil {
+		t.Fatal("genModel() succeeded with the model directory taken by a file")
+	}
+	if config, _ := os.ReadFile(filepath.Join(root, "godoconfig.json")); strings.Contains(string(config), "json_case") {
+		t.Fatalf("failed generation saved the JSON options: %s", config)
+	}
+}
+
+func TestModelTemplatesUseTheSessionsOfTheirDatabase(t *testing.T) {
+	tmpls, err := loadModelTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, content := range []string{tmpls.record, tmpls.list} {
+		data := template.ModelData{ModelPkg: "events", ProjectName: "example.com/project", ModelStructName: "Events"}
+		rendered, err := template.Render(content, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(rendered), "dbSession = mysqlx.NewTxSession()") || strings.Contains(string(rendered), "common/database") {
+			t.Fatalf("default database template = %s", rendered)
+		}
+
+		data.Database = "analytics"
+		rendered, err = template.Render(content, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, snippet := range []string{
+			`"example.com/project/internal/common/database"`,
+			`dbSession = database.NewSession("analytics")`,
+			`if database.AutoCreateTable("analytics") {`,
+		} {
+			if !strings.Contains(string(rendered), snippet) {
+				t.Fatalf("named database template does not contain %q:\n%s", snippet, rendered)
+			}
+		}
+	}
+}
+
+func TestGenModelRejectsInvalidDatabaseNames(t *testing.T) {
+	for _, name := range []string{"../analytics", "Analytics", "1db", "a/b"} {
+		if err := genModel([]string{"schema.sql"}, generateOptions{Database: name}); err == nil || !strings.Contains(err.Error(), "invalid database name") {
+			t.Fatalf("genModel(--db %q) error = %v", name, err)
+		}
+	}
 }
 
 func TestGenModelReportsInputErrors(t *testing.T) {
@@ -58,17 +178,48 @@ func TestGenModelReportsInputErrors(t *testing.T) {
 	if err := os.WriteFile(emptySQL, []byte("SELECT 1;"), 0o644); err != nil {
 		t.Fatal(err)
 	}
-	if err := genModel(emptySQL); err == nil || !strings.Contains(err.Error(), "no CREATE TABLE") {
+	if err := genModel([]string{emptySQL}, generateOptions{}); err == nil || !strings.Contains(err.Error(), "no CREATE TABLE") {
 		t.Fatalf("genModel(empty SQL) error = %v", err)
 	}
-	if err := genModel(filepath.Join(root, "missing.sql")); err == nil || !strings.Contains(err.Error(), "read SQL file") {
+	if err := genModel([]string{filepath.Join(root, "missing.sql")}, generateOptions{}); err == nil || !strings.Contains(err.Error(), "read SQL file") {
 		t.Fatalf("genModel(missing SQL) error = %v", err)
 	}
 	invalidConfig := filepath.Join(root, "invalid.json")
 	if err := os.WriteFile(invalidConfig, []byte("{"), 0o644); err != nil {
 		t.Fatal(err)
 	}
-	if err := genModel(invalidConfig); err == nil || !strings.Contains(err.Error(), "parse json") {
+	if err := genModel([]string{invalidConfig}, generateOptions{}); err == nil || !strings.Contains(err.Error(), "parse json") {
 		t.Fatalf("genModel(invalid config) error = %v", err)
 	}
 }
+
+func TestModelSourceReadsTheDSNFlagOrEnvironment(t *testing.T) {
+	t.Setenv(dsnEnv, "env:secret@tcp(db:3306)/app")
+	if paths, dsn, err := modelSource([]string{"schema.sql"}, "", ""); err != nil || len(paths) != 1 || paths[0] != "schema.sql" || dsn != "" {
+		t.Fatalf("modelSource(file) = %q, %q, %v", paths, dsn, err)
+	}
+	if _, dsn, err := modelSource(nil, "flag:secret@tcp(db:3306)/app", ""); err != nil || dsn != "flag:secret@tcp(db:3306)/app" {
+		t.Fatalf("modelSource(--dsn) = %q, %v", dsn, err)
+	}
+	if _, dsn, err := modelSource(nil, "", ""); err != nil || dsn != "env:secret@tcp(db:3306)/app" {
+		t.Fatalf("modelSource($%s) = %q, %v", dsnEnv, dsn, err)
+	}
+	if _, _, err := modelSource([]string{"schema.sql"}, "flag@tcp(db)/app", ""); err == nil || !strings.Contains(err.Error(), "not both") {
+		t.Fatalf("modelSource(file, --dsn) error = %v", err)
+	}
+	t.Setenv(dsnEnv, "")
+	if _, _, err := modelSource(nil, "", ""); err == nil || !strings.Contains(err.Error(), dsnEnv) {
+		t.Fatalf("modelSource() error = %v", err)
+	}
+}
+
+func TestGenModelFromDSNHidesThePassword(t *testing.T) {
+	src := tableSource{DSN: "root:hunter2@tcp(127.0.0.1:1)/app?timeout=1s", Schema: "reporting"}
+	if tablePrefix(src, "") != "" {
+		t.Fatal("tablePrefix() read a config file for a DSN source")
+	}
+	err := genModel(nil, generateOptions{DSN: src.DSN, Schema: src.Schema})
+	if err == nil || strings.Contains(err.Error(), "hunter2") || !strings.Contains(err.Error(), "root:****@tcp(127.0.0.1:1)/app") {
+		t.Fatalf("genModel(--dsn) error = %v", err)
+	}
+}
@@ -0,0 +1,432 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jiajia556/godo/internal/service"
+	"github.com/jiajia556/godo/internal/utils"
+)
+
+// ModelInfo describes a model package under internal/common/models as read
+// back from its source, for generators that build on existing models.
+type ModelInfo struct {
+	// Package is the package name and ImportPath its import path.
+	Package    string
+	ImportPath string
+	StructName string
+	TableName  string
+	// CreateDDL is the CREATE TABLE statement returned by GetCreateDDL, if any.
+	CreateDDL string
+	// View is set for read-only models of database views, which declare an
+	// IsView method.
+	View bool
+	// Fields lists the column fields in declaration order. Associations and
+	// fields ignored by gorm are left out.
+	Fields []ModelField
+}
+
+// ModelField is a column field of a model struct.
+type ModelField struct {
+	Name string
+	// Type is the field type as written outside the model package, so types
+	// declared by the model, such as enums, are qualified with its name.
+	Type string
+	// Imports lists the packages Type refers to.
+	Imports []string
+	Column  string
+	// JSON is the name of the field in the model's JSON tag. Hidden marks
+	// fields the tag leaves out of JSON.
+	JSON          string
+	Hidden        bool
+	PrimaryKey    bool
+	AutoIncrement bool
+	// Managed marks fields GORM sets itself: creation and update times, soft
+	// delete markers and read-only columns.
+	Managed bool
+	// AutoTime marks creation and update time fields. AutoTimeUnit is the unit
+	// of integer ones: "milli", "nano" or "" for seconds.
+	AutoTime     bool
+	AutoTimeUnit string
+	// NotNull, HasDefault, MaxLength, Enum and Set describe the column as
+	// declared by the gorm tag and the CREATE TABLE statement. MaxLength is the
+	// length of CHAR and VARCHAR columns; Enum lists the values of ENUM and SET
+	// columns, and Set marks SET columns.
+	NotNull    bool
+	HasDefault bool
+	MaxLength  int
+	Enum       []string
+	Set        bool
+}
+
+// errNoModel reports a package without a struct with a TableName method.
+var errNoModel = errors.New("no model with a TableName method")
+
+var charLengthRE = regexp.MustCompile(`(?i)^(?:national\s+)?(?:var)?char\s*\(\s*(\d+)\s*\)`)
+
+// PrimaryKey returns the primary key fields in declaration order.
+func (info *ModelInfo) PrimaryKey() []ModelField {
+	var keys []ModelField
+	for _, field := range info.Fields {
+		if field.PrimaryKey {
+			keys = append(keys, field)
+		}
+	}
+	return keys
+}
+
+// LoadModelInfo reads the model declared in internal/common/models/<modelPkg>:
+// the struct with a TableName method.
+func LoadModelInfo(modelPkg string) (*ModelInfo, error) {
+	if modelPkg == "" || path.Clean(modelPkg) != modelPkg || !filepath.IsLocal(modelPkg) {
+		return nil, fmt.Errorf("invalid model package %q", modelPkg)
+	}
+	dir, err := modelFilePath(modelPkg, "")
+	if err != nil {
+		return nil, err
+	}
+	projectName, err := service.GetProjectName()
+	if err != nil {
+		return nil, fmt.Errorf("get project name: %w", err)
+	}
+	return readModelInfo(dir, path.Join(projectName, "internal/common/models", filepath.ToSlash(modelPkg)))
+}
+
+// LoadModelInfos reads every model below internal/common/models, in package
+// path order. Packages without a model are skipped.
+func LoadModelInfos() ([]*ModelInfo, error) {
+	root, err := modelFilePath("", "")
+	if err != nil {
+		return nil, err
+	}
+	projectName, err := service.GetProjectName()
+	if err != nil {
+		return nil, fmt.Errorf("get project name: %w", err)
+	}
+	return readModelInfos(root, path.Join(projectName, "internal/common/models"))
+}
+
+func readModelInfos(root, importPath string) ([]*ModelInfo, error) {
+	var infos []*ModelInfo
+	err := filepath.WalkDir(root, func(dir string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.IsDir() || dir == root {
+			return err
+		}
+		rel, err := filepath.Rel(root, dir)
+		if err != nil {
+			return err
+		}
+		info, err := readModelInfo(dir, path.Join(importPath, filepath.ToSlash(rel)))
+		if errors.Is(err, errNoModel) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		infos = append(infos, info)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read models: %w", err)
+	}
+	return infos, nil
+}
+
+func readModelInfo(dir, importPath string) (*ModelInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read model package: %w", err)
+	}
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, fmt.Errorf("parse model file %s: %w", name, err)
+		}
+		files = append(files, file)
+	}
+
+	info := &ModelInfo{ImportPath: importPath}
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil {
+				continue
+			}
+			switch fn.Name.Name {
+			case "TableName":
+				if info.StructName != "" && info.StructName != receiverName(fn.Recv) {
+					return nil, fmt.Errorf("model package %s declares more than one model", dir)
+				}
+				info.StructName = receiverName(fn.Recv)
+				info.TableName = returnedString(fn)
+			case "GetCreateDDL":
+				info.CreateDDL = returnedString(fn)
+			case "IsView":
+				info.View = true
+			}
+		}
+	}
+	if info.StructName == "" {
+		return nil, fmt.Errorf("%w in %s", errNoModel, dir)
+	}
+
+	for _, file := range files {
+		structType, _ := findStructDecl(fset, file, info.StructName)
+		if structType == nil {
+			continue
+		}
+		info.Package = file.Name.Name
+		imports := fileImports(file)
+		for _, field := range structType.Fields.List {
+			fields, err := modelInfoFields(field, info.Package, importPath, imports)
+			if err != nil {
+				return nil, err
+			}
+			info.Fields = append(info.Fields, fields...)
+		}
+		markDefaultPrimaryKey(info.Fields)
+		if info.CreateDDL != "" {
+			if err := applyColumnDefinitions(info.Fields, info.CreateDDL); err != nil {
+				return nil, fmt.Errorf("parse create DDL of %s: %w", info.StructName, err)
+			}
+		}
+		return info, nil
+	}
+	return nil, fmt.Errorf("struct %s not found in %s", info.StructName, dir)
+}
+
+// returnedString returns the string constant returned by fn, or "".
+func returnedString(fn *ast.FuncDecl) string {
+	if fn.Body == nil || len(fn.Body.List) != 1 {
+		return ""
+	}
+	ret, ok := fn.Body.List[0].(*ast.ReturnStmt)
+	if !ok || len(ret.Results) != 1 {
+		return ""
+	}
+	lit, ok := ret.Results[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return ""
+	}
+	value, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return ""
+	}
+	return value
+}
+
+// fileImports maps the names files refer to their imports by to import paths.
+func fileImports(file *ast.File) map[string]string {
+	imports := make(map[string]string)
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := path.Base(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = importPath
+	}
+	return imports
+}
+
+func modelInfoFields(field *ast.Field, pkg, pkgPath string, imports map[string]string) ([]ModelField, error) {
+	var tag reflect.StructTag
+	if field.Tag != nil {
+		value, err := strconv.Unquote(field.Tag.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid struct tag %s: %w", field.Tag.Value, err)
+		}
+		tag = reflect.StructTag(value)
+	}
+	gormTag := tag.Get("gorm")
+	jsonName, _, _ := strings.Cut(tag.Get("json"), ",")
+	if len(field.Names) == 0 || gormTag == "-" || isAssociationTag(gormTag) {
+		return nil, nil
+	}
+
+	typeName, typeImports := qualifyType(field.Type, pkg, pkgPath, imports)
+	settings := gormSettings(gormTag)
+	_, primaryKey := settings["primarykey"]
+	_, autoIncrement := settings["autoincrement"]
+	createdUnit, created := settings["autocreatetime"]
+	updatedUnit, updated := settings["autoupdatetime"]
+	_, readOnly := settings["->"]
+	base := strings.TrimPrefix(typeName, "*")
+	managed := created || updated || readOnly || base == "gorm.DeletedAt" || base == "soft_delete.DeletedAt"
+	_, notNull := settings["notnull"]
+	_, hasDefault := settings["default"]
+	maxLength := 0
+	if base == "string" {
+		maxLength, _ = strconv.Atoi(settings["size"])
+	}
+
+	var fields []ModelField
+	for _, name := range field.Names {
+		if !name.IsExported() {
+			continue
+		}
+		column := settings["column"]
+		if column == "" {
+			column = utils.CamelToSnake(name.Name)
+		}
+		json := jsonName
+		if json == "" {
+			json = name.Name
+		}
+		fields = append(fields, ModelField{
+			Name:          name.Name,
+			Type:          typeName,
+			Imports:       typeImports,
+			Column:        column,
+			JSON:          json,
+			Hidden:        jsonName == "-",
+			PrimaryKey:    primaryKey,
+			AutoIncrement: autoIncrement,
+			Managed:       managed,
+			NotNull:       notNull,
+			HasDefault:    hasDefault,
+			MaxLength:     maxLength,
+			AutoTime:      created || updated,
+			AutoTimeUnit:  strings.ToLower(createdUnit + updatedUnit),
+		})
+	}
+	return fields, nil
+}
+
+// gormSettings splits a gorm tag into its lower-cased keys and values.
+func gormSettings(gormTag string) map[string]string {
+	settings := make(map[string]string)
+	for _, part := range strings.Split(gormTag, ";") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), ":")
+		key = strings.ToLower(strings.TrimSpace(key))
+		if key == "not null" {
+			key = "notnull"
+		}
+		if key != "" {
+			settings[key] = value
+		}
+	}
+	return settings
+}
+
+// applyColumnDefinitions completes fields with the nullability, defaults,
+// lengths and allowed values the CREATE TABLE statement declares.
+func applyColumnDefinitions(fields []ModelField, createDDL string) error {
+	table, err := parseSQL(createDDL)
+	if err != nil {
+		return err
+	}
+	columns := make(map[string]fieldInfo, len(table.fields))
+	for _, column := range table.fields {
+		columns[strings.ToLower(column.column)] = column
+	}
+	for i, field := range fields {
+		column, ok := columns[strings.ToLower(field.Column)]
+		if !ok {
+			continue
+		}
+		settings := gormSettings(column.gormTags)
+		if _, ok := settings["notnull"]; ok {
+			fields[i].NotNull = true
+		}
+		if _, ok := settings["default"]; ok {
+			fields[i].HasDefault = true
+		}
+		if matches := charLengthRE.FindStringSubmatch(column.sqlType); matches != nil {
+			fields[i].MaxLength, _ = strconv.Atoi(matches[1])
+		}
+		if column.enum != nil {
+			fields[i].Enum = column.enum.values
+			fields[i].Set = column.enum.set
+		}
+	}
+	return nil
+}
+
+func isAssociationTag(gormTag string) bool {
+	lower := strings.ToLower(gormTag)
+	for _, key := range []string{"foreignkey:", "references:", "many2many:", "polymorphic:"} {
+		if strings.Contains(lower, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// markDefaultPrimaryKey makes the id field the primary key, as GORM does, when
+// no field is tagged primaryKey. Integer ids are auto-incremented.
+func markDefaultPrimaryKey(fields []ModelField) {
+	for _, field := range fields {
+		if field.PrimaryKey {
+			return
+		}
+	}
+	for i, field := range fields {
+		if field.Column == "id" {
+			fields[i].PrimaryKey = true
+			fields[i].AutoIncrement = strings.Contains(field.Type, "int")
+			return
+		}
+	}
+}
+
+// qualifyType renders expr for use outside package pkg, imported as pkgPath,
+// and returns the import paths it needs.
+func qualifyType(expr ast.Expr, pkg, pkgPath string, imports map[string]string) (string, []string) {
+	needed := make(map[string]bool)
+	var qualify func(ast.Expr) ast.Expr
+	qualify = func(expr ast.Expr) ast.Expr {
+		switch e := expr.(type) {
+		case *ast.Ident:
+			if types.Universe.Lookup(e.Name) != nil {
+				return e
+			}
+			needed[pkgPath] = true
+			return &ast.SelectorExpr{X: ast.NewIdent(pkg), Sel: e}
+		case *ast.SelectorExpr:
+			if x, ok := e.X.(*ast.Ident); ok {
+				if importPath, ok := imports[x.Name]; ok {
+					needed[importPath] = true
+				}
+			}
+			return e
+		case *ast.StarExpr:
+			return &ast.StarExpr{X: qualify(e.X)}
+		case *ast.ArrayType:
+			return &ast.ArrayType{Len: e.Len, Elt: qualify(e.Elt)}
+		case *ast.MapType:
+			return &ast.MapType{Key: qualify(e.Key), Value: qualify(e.Value)}
+		}
+		return expr
+	}
+	typeName := types.ExprString(qualify(expr))
+
+	var paths []string
+	for importPath := range needed {
+		paths = append(paths, importPath)
+	}
+	sort.Strings(paths)
+	return typeName, paths
+}
@@ -0,0 +1,98 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadModelInfoQualifiesTypesAndClassifiesFields(t *testing.T) {
+	dir := t.TempDir()
+	model := "package user\n\nimport (\n\t\"time\"\n\n\t\"gorm.io/gorm\"\n)\n\n" +
+		"type UserStatus string\n\n" +
+		"type User struct {\n" +
+		"\tId        uint64     `gorm:\"column:id;autoIncrement;primaryKey\" json:\"id\"`\n" +
+		"\tName      string     `gorm:\"column:name;notNull\" json:\"name\"`\n" +
+		"\tStatus    UserStatus `gorm:\"column:status\" json:\"status,omitempty\"`\n" +
+		"\tLoginAt   *time.Time `gorm:\"column:login_at\" json:\"loginAt\"`\n" +
+		"\tCreatedAt time.Time  `gorm:\"column:created_at;autoCreateTime\" json:\"created_at\"`\n" +
+		"\tDeletedAt gorm.DeletedAt `gorm:\"column:deleted_at\" json:\"deleted_at\"`\n" +
+		"\tSecret    string     `gorm:\"column:secret\" json:\"-\"`\n" +
+		"\tOrders    []Order    `gorm:\"foreignKey:UserId\" json:\"orders,omitempty\"`\n" +
+		"}\n\n" +
+		"type Order struct{}\n\n" +
+		"func (data *User) TableName() string {\n\treturn \"user\"\n}\n\n" +
+		"func (data *User) GetCreateDDL() string {\n\treturn \"CREATE TABLE user (id bigint unsigned NOT NULL AUTO_INCREMENT, " +
+		"name varchar(64) NOT NULL, status enum('active','banned') NOT NULL DEFAULT 'active', PRIMARY KEY (id))\"\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, "model.go"), []byte(model), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := readModelInfo(dir, "example.com/project/internal/common/models/user")
+	if err != nil {
+		t.Fatalf("readModelInfo() error = %v", err)
+	}
+	if info.Package != "user" || info.StructName != "User" || info.TableName != "user" ||
+		!strings.HasPrefix(info.CreateDDL, "CREATE TABLE user (") {
+		t.Fatalf("info = %+v", info)
+	}
+	want := []ModelField{
+		{Name: "Id", Type: "uint64", Column: "id", JSON: "id", PrimaryKey: true, AutoIncrement: true, NotNull: true},
+		{Name: "Name", Type: "string", Column: "name", JSON: "name", NotNull: true, MaxLength: 64},
+		{Name: "Status", Type: "user.UserStatus", Imports: []string{"example.com/project/internal/common/models/user"},
+			Column: "status", JSON: "status", NotNull: true, HasDefault: true, Enum: []string{"active", "banned"}},
+		{Name: "LoginAt", Type: "*time.Time", Imports: []string{"time"}, Column: "login_at", JSON: "loginAt"},
+		{Name: "CreatedAt", Type: "time.Time", Imports: []string{"time"}, Column: "created_at", JSON: "created_at", Managed: true, AutoTime: true},
+		{Name: "DeletedAt", Type: "gorm.DeletedAt", Imports: []string{"gorm.io/gorm"}, Column: "deleted_at", JSON: "deleted_at", Managed: true},
+		{Name: "Secret", Type: "string", Column: "secret", JSON: "-", Hidden: true},
+	}
+	if !reflect.DeepEqual(info.Fields, want) {
+		t.Fatalf("fields = %+v\nwant %+v", info.Fields, want)
+	}
+	if keys := info.PrimaryKey(); len(keys) != 1 || keys[0].Name != "Id" {
+		t.Fatalf("PrimaryKey() = %+v", keys)
+	}
+}
+
+func TestReadModelInfoRequiresModel(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "list.go"), []byte("package user\n\ntype List struct{}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readModelInfo(dir, "example.com/project/internal/common/models/user"); err == nil {
+		t.Fatal("readModelInfo() error = nil")
+	}
+}
+
+func TestReadModelInfosWalksPackagesAndSkipsOthers(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"baselist.go":              "package models\n\ntype BaseList struct{}\n",
+		"user/model.go":            "package user\n\ntype User struct {\n\tId uint64\n\tCreatedAt int64 `gorm:\"autoCreateTime:milli\"`\n}\n\nfunc (data *User) TableName() string {\n\treturn \"users\"\n}\n",
+		"shared/util.go":           "package shared\n\nfunc Helper() {}\n",
+		"analytics/event/model.go": "package event\n\ntype Event struct {\n\tId uint64\n}\n\nfunc (data *Event) TableName() string {\n\treturn \"events\"\n}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	infos, err := readModelInfos(root, "example.com/project/internal/common/models")
+	if err != nil {
+		t.Fatalf("readModelInfos() error = %v", err)
+	}
+	if len(infos) != 2 || infos[0].ImportPath != "example.com/project/internal/common/models/analytics/event" ||
+		infos[1].TableName != "users" {
+		t.Fatalf("infos = %+v", infos)
+	}
+	if field := infos[1].Fields[1]; !field.AutoTime || field.AutoTimeUnit != "milli" {
+		t.Fatalf("created at = %+v", field)
+	}
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"github.com/jiajia556/godo/internal/service"
+	"github.com/jiajia556/godo/internal/utils"
+)
+
+// applyJSONTags names the JSON tags of fields after their columns in the given
+// case. Association fields have no column; they are named after the field and
+// always omitted when empty.
+func applyJSONTags(fields []fieldInfo, jsonCase string, omitEmpty bool) {
+	for i, f := range fields {
+		if f.column == "" {
+			fields[i].jsonTag = jsonName(f.name, jsonCase) + ",omitempty"
+			continue
+		}
+		fields[i].jsonTag = jsonName(f.column, jsonCase)
+		if omitEmpty && f.nullable {
+			fields[i].jsonTag += ",omitempty"
+		}
+	}
+}
+
+func jsonName(name, jsonCase string) string {
+	switch jsonCase {
+	case service.JSONCaseCamel:
+		return utils.ToCamelCase(name)
+	case service.JSONCaseLowerCamel:
+		return utils.ToLowerCamelCase(name)
+	case service.JSONCaseOriginal:
+		return name
+	default:
+		return utils.CamelToSnake(name)
+	}
+}
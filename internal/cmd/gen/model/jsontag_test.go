@@ -0,0 +1,45 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jiajia556/godo/internal/service"
+)
+
+func TestJSONTagCases(t *testing.T) {
+	sql := "CREATE TABLE `users` (`id` bigint unsigned NOT NULL, `userId` bigint NOT NULL, `display_name` varchar(64), PRIMARY KEY (`id`));"
+	tests := []struct {
+		jsonCase  string
+		omitEmpty bool
+		want      []string
+	}{
+		{"", false, []string{`json:"id"`, `json:"user_id"`, `json:"display_name"`}},
+		{service.JSONCaseCamel, false, []string{`json:"Id"`, `json:"UserId"`, `json:"DisplayName"`}},
+		{service.JSONCaseLowerCamel, false, []string{`json:"id"`, `json:"userId"`, `json:"displayName"`}},
+		{service.JSONCaseOriginal, false, []string{`json:"id"`, `json:"userId"`, `json:"display_name"`}},
+		{service.JSONCaseSnake, true, []string{`json:"id"`, `json:"user_id"`, `json:"display_name,omitempty"`}},
+	}
+	for _, tt := range tests {
+		structText, _, _, err := GenerateModelStruct(sql, StructOptions{JSONCase: tt.jsonCase, JSONOmitEmpty: tt.omitEmpty})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(structText, want) {
+				t.Errorf("case %q, omitempty %v: struct does not contain %s:\n%s", tt.jsonCase, tt.omitEmpty, want, structText)
+			}
+		}
+	}
+}
+
+func TestJSONTagsOfAssociations(t *testing.T) {
+	fields := []fieldInfo{
+		{name: "UserId", column: "user_id"},
+		{name: "User"},
+	}
+	applyJSONTags(fields, service.JSONCaseLowerCamel, true)
+	if fields[0].jsonTag != "userId" || fields[1].jsonTag != "user,omitempty" {
+		t.Fatalf("json tags = %q, %q", fields[0].jsonTag, fields[1].jsonTag)
+	}
+}
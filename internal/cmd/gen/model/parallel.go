@@ -0,0 +1,89 @@
+package model
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/jiajia556/godo/internal/utils"
+)
+
+// progressMinTotal is the number of tables from which gen model reports its
+// progress; smaller schemas finish before it would be read.
+const progressMinTotal = 50
+
+// workerCount returns the number of workers of --jobs, or the number of CPUs
+// when it is not positive.
+func workerCount(jobs int) int {
+	if jobs <= 0 {
+		return runtime.NumCPU()
+	}
+	return jobs
+}
+
+// runParallel calls fn for every index below n on at most jobs goroutines.
+// Once a call fails, the calls not started yet are skipped, and the error of
+// the lowest failed index is returned so that failures do not depend on
+// scheduling. fn stores its results by index to keep them in order.
+func runParallel(n, jobs int, progress *progress, fn func(i int) error) error {
+	errs := make([]error, n)
+	indexes := make(chan int)
+	var failed sync.Once
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for range min(workerCount(jobs), n) {
+		wg.Go(func() {
+			for i := range indexes {
+				if errs[i] = fn(i); errs[i] != nil {
+					failed.Do(func() { close(stop) })
+				}
+				progress.done()
+			}
+		})
+	}
+feed:
+	for i := range n {
+		select {
+		case indexes <- i:
+		case <-stop:
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// progress prints how many of total items are done at every tenth of them.
+// A nil progress reports nothing.
+type progress struct {
+	mu       sync.Mutex
+	label    string
+	total    int
+	finished int
+}
+
+// newProgress returns a progress of total items, or nil when there are too
+// few of them to report.
+func newProgress(label string, total int) *progress {
+	if total < progressMinTotal {
+		return nil
+	}
+	return &progress{label: label, total: total}
+}
+
+func (p *progress) done() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.finished++
+	if p.finished*10/p.total != (p.finished-1)*10/p.total {
+		utils.OutputInfof("%s: %d/%d", p.label, p.finished, p.total)
+	}
+}
@@ -0,0 +1,85 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunParallelBoundsWorkersAndKeepsOrder(t *testing.T) {
+	var running, peak atomic.Int32
+	results := make([]int, 40)
+	err := runParallel(len(results), 3, nil, func(i int) error {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		results[i] = i * i
+		running.Add(-1)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("runParallel() error = %v", err)
+	}
+	if peak.Load() > 3 {
+		t.Fatalf("peak workers = %d, want at most 3", peak.Load())
+	}
+	for i, result := range results {
+		if result != i*i {
+			t.Fatalf("results[%d] = %d", i, result)
+		}
+	}
+}
+
+func TestRunParallelReturnsTheFirstFailure(t *testing.T) {
+	var calls atomic.Int32
+	err := runParallel(1000, 4, nil, func(i int) error {
+		calls.Add(1)
+		if i >= 5 {
+			time.Sleep(time.Duration(10-i%10) * time.Microsecond)
+			return fmt.Errorf("table %d", i)
+		}
+		return nil
+	})
+	if err == nil || err.Error() != "table 5" {
+		t.Fatalf("runParallel() error = %v, want table 5", err)
+	}
+	if calls.Load() == 1000 {
+		t.Fatal("runParallel() did not stop after a failure")
+	}
+	if err := runParallel(0, 4, nil, func(int) error { return errors.New("called") }); err != nil {
+		t.Fatalf("runParallel(0) error = %v", err)
+	}
+}
+
+func TestGroupByModelDirKeepsTablesOfAPackageTogether(t *testing.T) {
+	definitions := []tableDefinition{
+		{CreateTable: "CREATE TABLE app_users (id int);"},
+		{CreateTable: "CREATE TABLE orders (id int);"},
+		{CreateTable: "not a table"},
+		{CreateTable: "CREATE TABLE users (id int);"},
+	}
+	groups := groupByModelDir(definitions, generateOptions{StructOptions: StructOptions{Prefix: "app_"}})
+	if got := fmt.Sprint(groups); got != "[[0 3] [1] [2]]" {
+		t.Fatalf("groups = %s", got)
+	}
+}
+
+func TestProgressIsOnlyReportedForLargeSchemas(t *testing.T) {
+	small := newProgress("generate models", progressMinTotal-1)
+	if small != nil {
+		t.Fatal("newProgress() reports a small schema")
+	}
+	small.done()
+	large := newProgress("generate models", progressMinTotal)
+	large.done()
+	if large == nil || large.finished != 1 {
+		t.Fatalf("progress = %+v", large)
+	}
+}
@@ -0,0 +1,654 @@
+package model
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// identifierPattern matches a backtick-quoted or bare MySQL identifier, and
+// tableNamePattern one optionally qualified by a schema, capturing the name.
+const (
+	identifierPattern = "(?:`(?:[^`]|``)+`|[A-Za-z0-9_$]+)"
+	tableNamePattern  = "(?:" + identifierPattern + "\\s*\\.\\s*)?(" + identifierPattern + ")"
+)
+
+var (
+	createTableLikeRE = regexp.MustCompile("(?is)^CREATE\\s+(?:TEMPORARY\\s+)?TABLE\\s+(IF\\s+NOT\\s+EXISTS\\s+)?" + tableNamePattern + "\\s*\\(?\\s*LIKE\\s+" + tableNamePattern + "\\s*\\)?$")
+	dropTableRE       = regexp.MustCompile("(?is)^DROP\\s+(?:TEMPORARY\\s+)?TABLES?\\s+(IF\\s+EXISTS\\s+)?(.+?)(?:\\s+(?:RESTRICT|CASCADE))?$")
+	renameTableRE     = regexp.MustCompile("(?is)^RENAME\\s+TABLES?\\s+(.+)$")
+	renamePairRE      = regexp.MustCompile("(?is)^" + tableNamePattern + "\\s+TO\\s+" + tableNamePattern + "$")
+	alterTableRE      = regexp.MustCompile("(?is)^ALTER\\s+(?:ONLINE\\s+)?(?:IGNORE\\s+)?TABLE\\s+" + tableNamePattern + "(?:\\s+(.*))?$")
+	createIndexRE     = regexp.MustCompile("(?is)^CREATE\\s+(?:(UNIQUE|FULLTEXT|SPATIAL)\\s+)?INDEX\\s+(" + identifierPattern + ")\\s+(?:USING\\s+\\w+\\s+)?ON\\s+" + tableNamePattern + "\\s*(\\(.*\\))[^)]*$")
+	dropIndexRE       = regexp.MustCompile("(?is)^DROP\\s+INDEX\\s+(" + identifierPattern + ")\\s+ON\\s+" + tableNamePattern + "(?:\\s+.*)?$")
+	tableNameRE       = regexp.MustCompile("(?s)^" + tableNamePattern + "$")
+	identifierRE      = regexp.MustCompile("^" + identifierPattern)
+	ifNotExistsRE     = regexp.MustCompile("(?i)^CREATE\\s+(?:TEMPORARY\\s+)?TABLE\\s+IF\\s+NOT\\s+EXISTS\\s")
+
+	columnPositionRE = regexp.MustCompile("(?is)\\s+(FIRST|AFTER\\s+(" + identifierPattern + "))\\s*$")
+	columnDefaultRE  = regexp.MustCompile("(?is)\\s+DEFAULT\\s+(?:'(?:[^'\\\\]|\\\\.|'')*'|\\([^)]*\\)|[^\\s,]+)")
+	tableOptionRE    = regexp.MustCompile("(?is)(?:\\bDEFAULT\\s+)?\\b(ENGINE|AUTO_INCREMENT|CHARSET|CHARACTER\\s+SET|COLLATE|COMMENT|ROW_FORMAT)\\b\\s*=?\\s*(?:'(?:[^'\\\\]|\\\\.|'')*'|[^\\s']+)")
+)
+
+// schemaReplay is an in-memory schema that DDL statements are applied to in
+// order. Tables and views keep the order they were created in.
+type schemaReplay struct {
+	tables []*replayedTable
+}
+
+// replayedTable is a table or view of the replayed schema. statement is the
+// CREATE TABLE statement a table was created from, kept verbatim until a later
+// statement changes the table.
+type replayedTable struct {
+	schema    *TableSchema
+	statement string
+	view      bool
+}
+
+// definitions returns the CREATE TABLE statement of every table and view.
+func (r *schemaReplay) definitions() []tableDefinition {
+	definitions := make([]tableDefinition, 0, len(r.tables))
+	for _, table := range r.tables {
+		statement := table.statement
+		if statement == "" {
+			statement = table.schema.CreateStatement()
+		}
+		definitions = append(definitions, tableDefinition{CreateTable: statement, View: table.view})
+	}
+	return definitions
+}
+
+func (r *schemaReplay) find(name string) int {
+	return slices.IndexFunc(r.tables, func(t *replayedTable) bool {
+		return strings.EqualFold(t.schema.Name, name)
+	})
+}
+
+// table returns the table named name for a statement changing it, which
+// stops the table from keeping its original CREATE TABLE statement.
+func (r *schemaReplay) table(name string) (*TableSchema, error) {
+	i := r.find(name)
+	if i < 0 {
+		return nil, fmt.Errorf("table %s does not exist", name)
+	}
+	if r.tables[i].view {
+		return nil, fmt.Errorf("%s is a view", name)
+	}
+	r.tables[i].statement = ""
+	return r.tables[i].schema, nil
+}
+
+// apply applies a statement. Statements other than CREATE, ALTER, DROP and
+// RENAME TABLE, CREATE, ALTER and DROP VIEW and CREATE and DROP INDEX are
+// ignored.
+func (r *schemaReplay) apply(statement string) error {
+	statement = strings.TrimSpace(statement)
+	var err error
+	switch {
+	case createViewRE.MatchString(statement):
+		err = r.createView(createViewRE.FindStringSubmatch(statement))
+	case dropViewRE.MatchString(statement):
+		err = r.dropViews(dropViewRE.FindStringSubmatch(statement))
+	case createTableLikeRE.MatchString(statement):
+		err = r.createTableLike(createTableLikeRE.FindStringSubmatch(statement))
+	case createTableHeaderRE.MatchString(statement):
+		err = r.createTable(statement)
+	case dropTableRE.MatchString(statement):
+		err = r.dropTables(dropTableRE.FindStringSubmatch(statement))
+	case renameTableRE.MatchString(statement):
+		err = r.renameTables(renameTableRE.FindStringSubmatch(statement)[1])
+	case alterTableRE.MatchString(statement):
+		matches := alterTableRE.FindStringSubmatch(statement)
+		err = r.alterTable(unquoteIdentifier(matches[1]), matches[2])
+	case createIndexRE.MatchString(statement):
+		err = r.createIndex(createIndexRE.FindStringSubmatch(statement))
+	case dropIndexRE.MatchString(statement):
+		matches := dropIndexRE.FindStringSubmatch(statement)
+		var table *TableSchema
+		if table, err = r.table(unquoteIdentifier(matches[2])); err == nil {
+			err = dropIndex(table, unquoteIdentifier(matches[1]))
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", statementSummary(statement), err)
+	}
+	return nil
+}
+
+// statementSummary returns the start of a statement for error messages.
+func statementSummary(statement string) string {
+	summary := strings.Join(strings.Fields(statement), " ")
+	if len(summary) > 60 {
+		summary = summary[:57] + "..."
+	}
+	return summary
+}
+
+func (r *schemaReplay) createTable(statement string) error {
+	schema, err := ParseCreateTable(statement)
+	if err != nil {
+		return err
+	}
+	if r.find(schema.Name) >= 0 {
+		if ifNotExistsRE.MatchString(statement) {
+			return nil
+		}
+		return fmt.Errorf("table %s already exists", schema.Name)
+	}
+	r.tables = append(r.tables, &replayedTable{schema: schema, statement: statement + ";"})
+	return nil
+}
+
+func (r *schemaReplay) createTableLike(matches []string) error {
+	name, source := unquoteIdentifier(matches[2]), unquoteIdentifier(matches[3])
+	if r.find(name) >= 0 {
+		if matches[1] != "" {
+			return nil
+		}
+		return fmt.Errorf("table %s already exists", name)
+	}
+	i := r.find(source)
+	if i < 0 {
+		return fmt.Errorf("table %s does not exist", source)
+	}
+	schema := cloneTableSchema(r.tables[i].schema)
+	schema.Name = name
+	r.tables = append(r.tables, &replayedTable{schema: schema})
+	return nil
+}
+
+func (r *schemaReplay) dropTables(matches []string) error {
+	for _, name := range splitFieldDefinitions(matches[2]) {
+		name, err := parseTableName(name)
+		if err != nil {
+			return err
+		}
+		i := r.find(name)
+		if i < 0 {
+			if matches[1] != "" {
+				continue
+			}
+			return fmt.Errorf("table %s does not exist", name)
+		}
+		if r.tables[i].view {
+			return fmt.Errorf("%s is a view", name)
+		}
+		r.tables = slices.Delete(r.tables, i, i+1)
+	}
+	return nil
+}
+
+func (r *schemaReplay) renameTables(list string) error {
+	for _, pair := range splitFieldDefinitions(list) {
+		matches := renamePairRE.FindStringSubmatch(strings.TrimSpace(pair))
+		if matches == nil {
+			return fmt.Errorf("invalid rename %q", pair)
+		}
+		if err := r.renameTable(unquoteIdentifier(matches[1]), unquoteIdentifier(matches[2])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renameTable renames a table or, with RENAME TABLE, a view.
+func (r *schemaReplay) renameTable(from, to string) error {
+	i := r.find(from)
+	if i < 0 {
+		return fmt.Errorf("table %s does not exist", from)
+	}
+	if j := r.find(to); j >= 0 && j != i {
+		return fmt.Errorf("table %s already exists", to)
+	}
+	r.tables[i].statement = ""
+	r.tables[i].schema.Name = to
+	return nil
+}
+
+func (r *schemaReplay) createIndex(matches []string) error {
+	table, err := r.table(unquoteIdentifier(matches[3]))
+	if err != nil {
+		return err
+	}
+	keyword := "KEY"
+	if matches[1] != "" {
+		keyword = strings.ToUpper(matches[1]) + " KEY"
+	}
+	return addIndex(table, keyword+" "+matches[2]+" "+matches[4])
+}
+
+// alterTable applies the comma-separated ALTER TABLE specs to the table.
+func (r *schemaReplay) alterTable(name, specs string) error {
+	table, err := r.table(name)
+	if err != nil {
+		return err
+	}
+	for _, spec := range splitFieldDefinitions(specs) {
+		if rename, ok := renameTableSpec(spec); ok {
+			if err := r.renameTable(table.Name, rename); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := alterTableSpec(table, spec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renameTableSpec reports the new name of a RENAME [TO|AS] spec.
+func renameTableSpec(spec string) (string, bool) {
+	words := strings.Fields(spec)
+	if len(words) < 2 || !strings.EqualFold(words[0], "RENAME") {
+		return "", false
+	}
+	rest := strings.TrimSpace(spec[len(words[0])+1:])
+	switch strings.ToUpper(words[1]) {
+	case "COLUMN", "INDEX", "KEY":
+		return "", false
+	case "TO", "AS":
+		rest = strings.TrimSpace(rest[len(words[1]):])
+	}
+	name, err := parseTableName(rest)
+	return name, err == nil
+}
+
+// alterTableSpec applies an ALTER TABLE spec other than RENAME [TO|AS].
+func alterTableSpec(table *TableSchema, spec string) error {
+	keyword, rest := cutKeyword(spec)
+	switch keyword {
+	case "ADD":
+		return addDefinition(table, rest)
+	case "MODIFY":
+		rest = trimKeyword(rest, "COLUMN")
+		column, position, err := parseColumnDefinition(rest)
+		if err != nil {
+			return err
+		}
+		return replaceColumn(table, column.Name, column, position)
+	case "CHANGE":
+		rest = trimKeyword(rest, "COLUMN")
+		old, rest := cutIdentifier(rest)
+		column, position, err := parseColumnDefinition(rest)
+		if err != nil {
+			return err
+		}
+		return replaceColumn(table, old, column, position)
+	case "DROP":
+		return dropDefinition(table, rest)
+	case "RENAME":
+		return renameDefinition(table, rest)
+	case "ALTER":
+		return alterColumn(table, trimKeyword(rest, "COLUMN"))
+	case "ALGORITHM", "LOCK", "FORCE":
+		return nil
+	}
+	if tableOptionRE.MatchString(spec) && strings.TrimSpace(tableOptionRE.ReplaceAllString(spec, "")) == "" {
+		setTableOptions(table, spec)
+		return nil
+	}
+	return fmt.Errorf("unsupported ALTER TABLE clause: %s", spec)
+}
+
+// addDefinition applies ADD [COLUMN] of one or a parenthesized list of
+// columns, or ADD of an index or constraint.
+func addDefinition(table *TableSchema, def string) error {
+	if _, ok, err := parseIndexDefinition(def); err != nil {
+		return err
+	} else if ok {
+		return addIndex(table, def)
+	}
+	if isTableConstraint(def) {
+		table.Constraints = append(table.Constraints, strings.TrimSpace(def))
+		return nil
+	}
+	def = trimKeyword(def, "COLUMN")
+	if strings.HasPrefix(def, "(") && strings.HasSuffix(def, ")") {
+		for _, columnDef := range splitFieldDefinitions(def[1 : len(def)-1]) {
+			if err := addColumn(table, columnDef); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return addColumn(table, def)
+}
+
+func addColumn(table *TableSchema, def string) error {
+	column, position, err := parseColumnDefinition(def)
+	if err != nil {
+		return err
+	}
+	if _, ok := table.Column(column.Name); ok {
+		return fmt.Errorf("column %s.%s already exists", table.Name, column.Name)
+	}
+	return insertColumn(table, column, position)
+}
+
+func addIndex(table *TableSchema, def string) error {
+	index, _, err := parseIndexDefinition(def)
+	if err != nil {
+		return err
+	}
+	if slices.ContainsFunc(table.Indexes, func(i IndexSchema) bool { return strings.EqualFold(i.Name, index.Name) }) {
+		return fmt.Errorf("index %s of table %s already exists", index.Name, table.Name)
+	}
+	table.Indexes = append(table.Indexes, index)
+	return nil
+}
+
+// replaceColumn replaces the column named old with column, moving it when a
+// position is given.
+func replaceColumn(table *TableSchema, old string, column ColumnSchema, position string) error {
+	i := columnIndex(table, old)
+	if i < 0 {
+		return fmt.Errorf("column %s.%s does not exist", table.Name, old)
+	}
+	if !strings.EqualFold(old, column.Name) {
+		if _, ok := table.Column(column.Name); ok {
+			return fmt.Errorf("column %s.%s already exists", table.Name, column.Name)
+		}
+		renameIndexColumns(table, old, column.Name)
+	}
+	if position == "" {
+		table.Columns[i] = column
+		return nil
+	}
+	table.Columns = slices.Delete(table.Columns, i, i+1)
+	return insertColumn(table, column, position)
+}
+
+// insertColumn inserts column at position: FIRST, AFTER <column>, or last
+// when empty.
+func insertColumn(table *TableSchema, column ColumnSchema, position string) error {
+	at := len(table.Columns)
+	if keyword, rest := cutKeyword(position); keyword == "FIRST" {
+		at = 0
+	} else if keyword == "AFTER" {
+		after := unquoteIdentifier(strings.TrimSpace(rest))
+		if at = columnIndex(table, after); at < 0 {
+			return fmt.Errorf("column %s.%s does not exist", table.Name, after)
+		}
+		at++
+	}
+	table.Columns = slices.Insert(table.Columns, at, column)
+	return nil
+}
+
+func dropDefinition(table *TableSchema, rest string) error {
+	keyword, name := cutKeyword(rest)
+	name = unquoteIdentifier(strings.TrimSpace(name))
+	switch keyword {
+	case "PRIMARY":
+		return dropIndex(table, IndexPrimary)
+	case "INDEX", "KEY":
+		return dropIndex(table, name)
+	case "FOREIGN":
+		return dropConstraint(table, unquoteIdentifier(strings.TrimSpace(trimKeyword(name, "KEY"))))
+	case "CONSTRAINT", "CHECK":
+		return dropConstraint(table, name)
+	case "COLUMN":
+		return dropColumn(table, name)
+	}
+	return dropColumn(table, unquoteIdentifier(strings.TrimSpace(rest)))
+}
+
+// dropColumn drops a column and removes it from the indexes, dropping the
+// indexes left without columns as MySQL does.
+func dropColumn(table *TableSchema, name string) error {
+	i := columnIndex(table, name)
+	if i < 0 {
+		return fmt.Errorf("column %s.%s does not exist", table.Name, name)
+	}
+	table.Columns = slices.Delete(table.Columns, i, i+1)
+	indexes := table.Indexes[:0]
+	for _, index := range table.Indexes {
+		index.Columns = slices.DeleteFunc(index.Columns, func(c IndexColumn) bool { return strings.EqualFold(c.Name, name) })
+		if len(index.Columns) > 0 {
+			indexes = append(indexes, index)
+		}
+	}
+	table.Indexes = indexes
+	return nil
+}
+
+func dropIndex(table *TableSchema, name string) error {
+	i := slices.IndexFunc(table.Indexes, func(index IndexSchema) bool { return strings.EqualFold(index.Name, name) })
+	if i < 0 {
+		return fmt.Errorf("index %s of table %s does not exist", name, table.Name)
+	}
+	table.Indexes = slices.Delete(table.Indexes, i, i+1)
+	return nil
+}
+
+func dropConstraint(table *TableSchema, name string) error {
+	i := slices.IndexFunc(table.Constraints, func(def string) bool {
+		_, symbol := stripConstraintName(def)
+		return strings.EqualFold(symbol, name)
+	})
+	if i < 0 {
+		return fmt.Errorf("constraint %s of table %s does not exist", name, table.Name)
+	}
+	table.Constraints = slices.Delete(table.Constraints, i, i+1)
+	return nil
+}
+
+// renameDefinition applies RENAME COLUMN and RENAME INDEX|KEY.
+func renameDefinition(table *TableSchema, rest string) error {
+	keyword, rest := cutKeyword(rest)
+	old, rest := cutIdentifier(rest)
+	to, name := cutKeyword(rest)
+	name = unquoteIdentifier(strings.TrimSpace(name))
+	if to != "TO" || old == "" || name == "" {
+		return fmt.Errorf("unsupported ALTER TABLE clause: RENAME %s", rest)
+	}
+	switch keyword {
+	case "COLUMN":
+		i := columnIndex(table, old)
+		if i < 0 {
+			return fmt.Errorf("column %s.%s does not exist", table.Name, old)
+		}
+		table.Columns[i].Name = name
+		renameIndexColumns(table, old, name)
+	case "INDEX", "KEY":
+		i := slices.IndexFunc(table.Indexes, func(index IndexSchema) bool { return strings.EqualFold(index.Name, old) })
+		if i < 0 {
+			return fmt.Errorf("index %s of table %s does not exist", old, table.Name)
+		}
+		table.Indexes[i].Name = name
+	default:
+		return fmt.Errorf("unsupported ALTER TABLE clause: RENAME %s", keyword)
+	}
+	return nil
+}
+
+// alterColumn applies ALTER [COLUMN] <column> SET DEFAULT, DROP DEFAULT and
+// SET VISIBLE|INVISIBLE.
+func alterColumn(table *TableSchema, rest string) error {
+	name, rest := cutIdentifier(rest)
+	i := columnIndex(table, name)
+	if i < 0 {
+		return fmt.Errorf("column %s.%s does not exist", table.Name, name)
+	}
+	column := &table.Columns[i]
+	action := strings.ToUpper(strings.Join(strings.Fields(rest), " "))
+	switch {
+	case action == "DROP DEFAULT":
+		column.Definition = columnDefaultRE.ReplaceAllString(column.Definition, "")
+	case strings.HasPrefix(action, "SET DEFAULT "):
+		value := strings.TrimSpace(rest)[len("SET DEFAULT "):]
+		column.Definition = columnDefaultRE.ReplaceAllString(column.Definition, "") + " DEFAULT " + strings.TrimSpace(value)
+	case action == "SET VISIBLE" || action == "SET INVISIBLE":
+	default:
+		return fmt.Errorf("unsupported ALTER COLUMN clause: %s", rest)
+	}
+	return nil
+}
+
+// setTableOptions replaces the table options set by spec.
+func setTableOptions(table *TableSchema, spec string) {
+	for _, option := range tableOptionRE.FindAllStringSubmatch(spec, -1) {
+		key := tableOptionKey(option[1])
+		table.Options = strings.TrimSpace(tableOptionRE.ReplaceAllStringFunc(table.Options, func(existing string) string {
+			if tableOptionKey(tableOptionRE.FindStringSubmatch(existing)[1]) == key {
+				return ""
+			}
+			return existing
+		}))
+		table.Options = strings.TrimSpace(table.Options + " " + strings.TrimSpace(option[0]))
+	}
+	table.Options = strings.Join(strings.Fields(table.Options), " ")
+}
+
+func tableOptionKey(name string) string {
+	key := strings.ToUpper(strings.Join(strings.Fields(name), " "))
+	if key == "CHARACTER SET" {
+		return "CHARSET"
+	}
+	return key
+}
+
+// parseColumnDefinition parses "<column> <definition> [FIRST|AFTER <column>]".
+func parseColumnDefinition(def string) (ColumnSchema, string, error) {
+	position := ""
+	if loc := columnPositionRE.FindStringSubmatchIndex(def); loc != nil {
+		position = def[loc[2]:loc[3]]
+		def = def[:loc[0]]
+	}
+	matches := columnDefRE.FindStringSubmatch(def)
+	if matches == nil {
+		return ColumnSchema{}, "", fmt.Errorf("invalid column definition: %s", def)
+	}
+	name := matches[1]
+	if name == "" {
+		name = matches[2]
+	}
+	return ColumnSchema{Name: name, Definition: strings.TrimSpace(matches[3])}, position, nil
+}
+
+func columnIndex(table *TableSchema, name string) int {
+	return slices.IndexFunc(table.Columns, func(c ColumnSchema) bool { return strings.EqualFold(c.Name, name) })
+}
+
+func renameIndexColumns(table *TableSchema, old, name string) {
+	for i := range table.Indexes {
+		for j := range table.Indexes[i].Columns {
+			if strings.EqualFold(table.Indexes[i].Columns[j].Name, old) {
+				table.Indexes[i].Columns[j].Name = name
+			}
+		}
+	}
+}
+
+func cloneTableSchema(t *TableSchema) *TableSchema {
+	clone := *t
+	clone.Columns = slices.Clone(t.Columns)
+	clone.Indexes = make([]IndexSchema, len(t.Indexes))
+	for i, index := range t.Indexes {
+		index.Columns = slices.Clone(index.Columns)
+		clone.Indexes[i] = index
+	}
+	clone.Constraints = slices.Clone(t.Constraints)
+	return &clone
+}
+
+// cutKeyword splits s into its upper-cased first word and the rest. Words end
+// at spaces, parentheses and equal signs.
+func cutKeyword(s string) (string, string) {
+	s = strings.TrimSpace(s)
+	end := strings.IndexFunc(s, func(r rune) bool { return r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '(' || r == '=' })
+	if end < 0 {
+		end = len(s)
+	}
+	return strings.ToUpper(s[:end]), strings.TrimSpace(s[end:])
+}
+
+// trimKeyword removes keyword from the start of s when it is a whole word.
+func trimKeyword(s, keyword string) string {
+	s = strings.TrimSpace(s)
+	if word, rest := cutKeyword(s); word == keyword && rest != "" {
+		return rest
+	}
+	return s
+}
+
+// cutIdentifier splits s into its unquoted first identifier and the rest.
+func cutIdentifier(s string) (string, string) {
+	s = strings.TrimSpace(s)
+	loc := identifierRE.FindStringIndex(s)
+	if loc == nil {
+		return "", s
+	}
+	return unquoteIdentifier(s[:loc[1]]), strings.TrimSpace(s[loc[1]:])
+}
+
+func parseTableName(s string) (string, error) {
+	matches := tableNameRE.FindStringSubmatch(strings.TrimSpace(s))
+	if matches == nil {
+		return "", fmt.Errorf("invalid table name %q", strings.TrimSpace(s))
+	}
+	return unquoteIdentifier(matches[1]), nil
+}
+
+func unquoteIdentifier(name string) string {
+	if len(name) >= 2 && name[0] == '`' && name[len(name)-1] == '`' {
+		return strings.ReplaceAll(name[1:len(name)-1], "``", "`")
+	}
+	return name
+}
+
+// sqlFiles expands directories among paths to the .sql files directly in
+// them, leaving out .down.sql migrations. A directory's files are ordered by
+// their leading number, such as a migration version, and then by name.
+func sqlFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("read SQL directory: %w", err)
+		}
+		var names []string
+		for _, entry := range entries {
+			name := entry.Name()
+			if !entry.IsDir() && isSQLFile(name) && !strings.HasSuffix(strings.ToLower(name), ".down.sql") {
+				names = append(names, name)
+			}
+		}
+		slices.SortFunc(names, compareSQLFileNames)
+		for _, name := range names {
+			files = append(files, filepath.Join(path, name))
+		}
+	}
+	return files, nil
+}
+
+func compareSQLFileNames(a, b string) int {
+	va, oka := leadingNumber(a)
+	vb, okb := leadingNumber(b)
+	if oka && okb && va != vb {
+		if va < vb {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func leadingNumber(name string) (uint64, bool) {
+	end := strings.IndexFunc(name, func(r rune) bool { return r < '0' || r > '9' })
+	if end < 0 {
+		end = len(name)
+	}
+	n, err := strconv.ParseUint(name[:end], 10, 64)
+	return n, err == nil
+}
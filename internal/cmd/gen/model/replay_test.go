@@ -0,0 +1,163 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func replayStatements(t *testing.T, statements ...string) []*TableSchema {
+	t.Helper()
+	var replay schemaReplay
+	for _, statement := range statements {
+		if err := replay.apply(statement); err != nil {
+			t.Fatalf("apply(%q) error = %v", statement, err)
+		}
+	}
+	var tables []*TableSchema
+	for _, definition := range replay.definitions() {
+		table, err := ParseCreateTable(definition.CreateTable)
+		if err != nil {
+			t.Fatalf("ParseCreateTable(%q) error = %v", definition.CreateTable, err)
+		}
+		tables = append(tables, table)
+	}
+	return tables
+}
+
+func columnNames(table *TableSchema) string {
+	var names []string
+	for _, column := range table.Columns {
+		names = append(names, column.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestSchemaReplayAppliesAlterTable(t *testing.T) {
+	tables := replayStatements(t,
+		"CREATE TABLE `users` (`id` bigint NOT NULL, `name` varchar(32) NOT NULL, `nick` varchar(16), `legacy` int, "+
+			"PRIMARY KEY (`id`), KEY `idx_legacy_name` (`legacy`, `name`)) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='old'",
+		"ALTER TABLE users ADD COLUMN `email` varchar(255) NOT NULL DEFAULT '' COMMENT 'first, after' AFTER `name`, "+
+			"ADD UNIQUE KEY `uk_email` (`email`), MODIFY `name` varchar(64) NOT NULL",
+		"ALTER TABLE `app`.`users` CHANGE COLUMN `nick` `nickname` varchar(32) FIRST, DROP COLUMN legacy, "+
+			"RENAME COLUMN email TO mail, RENAME INDEX uk_email TO uk_mail, COMMENT = 'people', ALGORITHM=INPLACE",
+		"ALTER TABLE users ALTER COLUMN mail SET DEFAULT 'none', ADD (`age` int, `bio` text)",
+		"CREATE INDEX idx_age ON users (age)",
+		"INSERT INTO users (id, name) VALUES (1, 'a')",
+	)
+	if len(tables) != 1 {
+		t.Fatalf("tables = %d", len(tables))
+	}
+	users := tables[0]
+	if got := columnNames(users); got != "nickname,id,name,mail,age,bio" {
+		t.Fatalf("columns = %s", got)
+	}
+	if name, _ := users.Column("name"); name.Definition != "varchar(64) NOT NULL" {
+		t.Fatalf("name = %q", name.Definition)
+	}
+	if mail, _ := users.Column("mail"); mail.Definition != "varchar(255) NOT NULL COMMENT 'first, after' DEFAULT 'none'" {
+		t.Fatalf("mail = %q", mail.Definition)
+	}
+	var indexes []string
+	for _, index := range users.Indexes {
+		indexes = append(indexes, index.Definition())
+	}
+	if got := strings.Join(indexes, "; "); got != "PRIMARY KEY (`id`); KEY `idx_legacy_name` (`name`); UNIQUE KEY `uk_mail` (`mail`); KEY `idx_age` (`age`)" {
+		t.Fatalf("indexes = %s", got)
+	}
+	if users.Options != "ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT = 'people'" {
+		t.Fatalf("options = %s", users.Options)
+	}
+}
+
+func TestSchemaReplayAppliesTableStatements(t *testing.T) {
+	tables := replayStatements(t,
+		"CREATE TABLE a (id int)",
+		"CREATE TABLE b (id int, a_id int, CONSTRAINT fk_a FOREIGN KEY (a_id) REFERENCES a (id))",
+		"CREATE TABLE IF NOT EXISTS a (other int)",
+		"CREATE TABLE c LIKE b",
+		"RENAME TABLE a TO accounts, b TO orders",
+		"ALTER TABLE orders RENAME TO purchases, DROP FOREIGN KEY fk_a",
+		"DROP TABLE IF EXISTS c, missing",
+	)
+	var names []string
+	for _, table := range tables {
+		names = append(names, table.Name)
+	}
+	if got := strings.Join(names, ","); got != "accounts,purchases" {
+		t.Fatalf("tables = %s", got)
+	}
+	if len(tables[1].Constraints) != 0 || columnNames(tables[0]) != "id" {
+		t.Fatalf("tables = %+v", tables)
+	}
+}
+
+func TestSchemaReplayReportsInvalidStatements(t *testing.T) {
+	tests := []struct {
+		statements []string
+		want       string
+	}{
+		{[]string{"ALTER TABLE users ADD id int"}, "ALTER TABLE users ADD id int: table users does not exist"},
+		{[]string{"CREATE TABLE users (id int)", "CREATE TABLE users (id int)"}, "table users already exists"},
+		{[]string{"CREATE TABLE users (id int)", "ALTER TABLE users DROP name"}, "column users.name does not exist"},
+		{[]string{"CREATE TABLE users (id int)", "ALTER TABLE users ADD id bigint"}, "column users.id already exists"},
+		{[]string{"CREATE TABLE users (id int)", "ALTER TABLE users ADD name text AFTER nope"}, "column users.nope does not exist"},
+		{[]string{"CREATE TABLE users (id int)", "ALTER TABLE users PARTITION BY HASH(id)"}, "unsupported ALTER TABLE clause"},
+		{[]string{"CREATE TABLE users (id int)", "DROP TABLE users", "DROP TABLE users"}, "table users does not exist"},
+		{[]string{"CREATE TABLE users (id int)", "DROP INDEX idx_id ON users"}, "index idx_id of table users does not exist"},
+	}
+	for _, tt := range tests {
+		var replay schemaReplay
+		var err error
+		for _, statement := range tt.statements {
+			if err = replay.apply(statement); err != nil {
+				break
+			}
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("apply(%q) error = %v, want %q", tt.statements, err, tt.want)
+		}
+	}
+}
+
+func TestExtractCreateTablesFromSqlFileReplaysDirectories(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"2_add_email.up.sql":   "ALTER TABLE users ADD COLUMN email varchar(255);",
+		"2_add_email.down.sql": "ALTER TABLE users DROP COLUMN email;",
+		"10_drop_logs.up.sql":  "DROP TABLE logs;",
+		"1_init.up.sql":        "CREATE TABLE users (id bigint NOT NULL);\nCREATE TABLE logs (id bigint);\nCREATE TABLE tags (id int);",
+		"notes.txt":            "DROP TABLE users;",
+		"3_rename_tags.up.sql": "RENAME TABLE tags TO labels;",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	extra := filepath.Join(t.TempDir(), "extra.sql")
+	if err := os.WriteFile(extra, []byte("ALTER TABLE labels ADD name varchar(32);"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	definitions, err := extractCreateTablesFromSqlFile(dir, extra)
+	if err != nil {
+		t.Fatalf("extractCreateTablesFromSqlFile() error = %v", err)
+	}
+	want := []string{
+		"CREATE TABLE `users` (\n  `id` bigint NOT NULL,\n  `email` varchar(255)\n);",
+		"CREATE TABLE `labels` (\n  `id` int,\n  `name` varchar(32)\n);",
+	}
+	if statements := tableStatements(definitions); strings.Join(statements, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("statements = %q", statements)
+	}
+	if !(tableSource{Paths: []string{dir, extra}}).isSQL() || (tableSource{Paths: []string{"config.yaml"}}).isSQL() {
+		t.Fatal("isSQL() misclassified the paths")
+	}
+
+	if _, err := extractCreateTablesFromSqlFile(dir, filepath.Join(dir, "1_init.up.sql")); err == nil ||
+		!strings.Contains(err.Error(), "1_init.up.sql: CREATE TABLE users") {
+		t.Fatalf("extractCreateTablesFromSqlFile(replayed twice) error = %v", err)
+	}
+}
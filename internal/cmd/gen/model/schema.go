@@ -0,0 +1,310 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Index kinds recognised in CREATE TABLE statements.
+const (
+	IndexPrimary  = "PRIMARY"
+	IndexUnique   = "UNIQUE"
+	IndexPlain    = "INDEX"
+	IndexFulltext = "FULLTEXT"
+	IndexSpatial  = "SPATIAL"
+)
+
+// TableSchema is the structured form of a CREATE TABLE statement.
+type TableSchema struct {
+	Name    string         `json:"name"`
+	Columns []ColumnSchema `json:"columns"`
+	Indexes []IndexSchema  `json:"indexes,omitempty"`
+	// Constraints holds table-level definitions that are neither columns nor
+	// indexes, such as FOREIGN KEY and CHECK constraints, verbatim.
+	Constraints []string `json:"constraints,omitempty"`
+	// Options holds the table options following the column list, such as
+	// ENGINE=InnoDB DEFAULT CHARSET=utf8mb4.
+	Options string `json:"options,omitempty"`
+}
+
+// ColumnSchema is a column name and the rest of its definition, e.g.
+// "varchar(64) NOT NULL DEFAULT ”".
+type ColumnSchema struct {
+	Name       string `json:"name"`
+	Definition string `json:"definition"`
+}
+
+// IndexSchema is a PRIMARY KEY, UNIQUE, plain, FULLTEXT or SPATIAL index.
+type IndexSchema struct {
+	Name    string        `json:"name,omitempty"`
+	Kind    string        `json:"kind"`
+	Columns []IndexColumn `json:"columns"`
+}
+
+// ForeignKeySchema is a FOREIGN KEY constraint.
+type ForeignKeySchema struct {
+	Name       string
+	Columns    []string
+	RefTable   string
+	RefColumns []string
+}
+
+// IndexColumn is an indexed column with an optional prefix length.
+type IndexColumn struct {
+	Name   string `json:"name"`
+	Length int    `json:"length,omitempty"`
+}
+
+var (
+	indexHeaderRE = regexp.MustCompile("(?i)^(PRIMARY\\s+KEY|UNIQUE(?:\\s+(?:KEY|INDEX))?|(?:FULLTEXT|SPATIAL)(?:\\s+(?:KEY|INDEX))?|KEY|INDEX)(?:\\s+(?:`([^`]+)`|([A-Za-z0-9_$]+)))?(?:\\s+USING\\s+\\w+)?\\s*\\(")
+	indexColumnRE = regexp.MustCompile("(?i)^(?:`([^`]+)`|([A-Za-z0-9_$]+))\\s*(?:\\(\\s*(\\d+)\\s*\\))?(?:\\s+(?:ASC|DESC))?$")
+	constraintRE  = regexp.MustCompile("(?i)^CONSTRAINT\\s+(?:`[^`]+`|[A-Za-z0-9_$]+)?\\s*")
+	foreignKeyRE  = regexp.MustCompile("(?is)^FOREIGN\\s+KEY\\s*(?:`[^`]+`|[A-Za-z0-9_$]+)?\\s*\\(([^)]*)\\)\\s*REFERENCES\\s+(?:(?:`[^`]+`|[A-Za-z0-9_$]+)\\s*\\.\\s*)?(?:`([^`]+)`|([A-Za-z0-9_$]+))\\s*\\(([^)]*)\\)")
+	columnDefRE   = regexp.MustCompile("(?s)^\\s*(?:`([^`]+)`|([A-Za-z0-9_$]+))\\s+(.+)$")
+)
+
+// ParseCreateTable parses a CREATE TABLE statement into its columns, indexes,
+// remaining constraints and table options.
+func ParseCreateTable(sql string) (*TableSchema, error) {
+	tableName, err := extractTableName(sql)
+	if err != nil {
+		return nil, err
+	}
+	defs, options, err := extractTableBody(sql)
+	if err != nil {
+		return nil, err
+	}
+
+	table := &TableSchema{Name: tableName, Options: options}
+	for _, def := range defs {
+		if index, ok, err := parseIndexDefinition(def); err != nil {
+			return nil, fmt.Errorf("table %s: %w", tableName, err)
+		} else if ok {
+			table.Indexes = append(table.Indexes, index)
+			continue
+		}
+		if isTableConstraint(def) {
+			table.Constraints = append(table.Constraints, def)
+			continue
+		}
+		matches := columnDefRE.FindStringSubmatch(def)
+		if matches == nil {
+			return nil, fmt.Errorf("table %s: invalid column definition: %s", tableName, def)
+		}
+		name := matches[1]
+		if name == "" {
+			name = matches[2]
+		}
+		table.Columns = append(table.Columns, ColumnSchema{Name: name, Definition: strings.TrimSpace(matches[3])})
+	}
+	return table, nil
+}
+
+// parseIndexDefinition parses an index definition. It reports false for
+// definitions that are not indexes (columns, FOREIGN KEY, CHECK).
+func parseIndexDefinition(def string) (IndexSchema, bool, error) {
+	def, symbol := stripConstraintName(strings.TrimSpace(def))
+	loc := indexHeaderRE.FindStringSubmatchIndex(def)
+	if loc == nil {
+		return IndexSchema{}, false, nil
+	}
+	keyword := strings.ToUpper(strings.Join(strings.Fields(def[loc[2]:loc[3]]), " "))
+	name := ""
+	if loc[4] >= 0 {
+		name = def[loc[4]:loc[5]]
+	} else if loc[6] >= 0 {
+		name = def[loc[6]:loc[7]]
+	}
+
+	open := loc[1] - 1
+	closing := matchingParen(def, open)
+	if closing < 0 {
+		return IndexSchema{}, false, fmt.Errorf("invalid index definition: %s", def)
+	}
+
+	index := IndexSchema{Name: name}
+	switch {
+	case keyword == "PRIMARY KEY":
+		index.Kind = IndexPrimary
+		index.Name = IndexPrimary
+	case strings.HasPrefix(keyword, "UNIQUE"):
+		index.Kind = IndexUnique
+	case strings.HasPrefix(keyword, "FULLTEXT"):
+		index.Kind = IndexFulltext
+	case strings.HasPrefix(keyword, "SPATIAL"):
+		index.Kind = IndexSpatial
+	default:
+		index.Kind = IndexPlain
+	}
+
+	for _, part := range splitFieldDefinitions(def[open+1 : closing]) {
+		matches := indexColumnRE.FindStringSubmatch(strings.TrimSpace(part))
+		if matches == nil {
+			return IndexSchema{}, false, fmt.Errorf("unsupported index column %q in: %s", part, def)
+		}
+		column := IndexColumn{Name: matches[1]}
+		if column.Name == "" {
+			column.Name = matches[2]
+		}
+		if matches[3] != "" {
+			column.Length, _ = strconv.Atoi(matches[3])
+		}
+		index.Columns = append(index.Columns, column)
+	}
+	if len(index.Columns) == 0 {
+		return IndexSchema{}, false, fmt.Errorf("index without columns: %s", def)
+	}
+	if index.Name == "" {
+		index.Name = symbol
+	}
+	if index.Name == "" {
+		// MySQL names an anonymous index after its first column.
+		index.Name = index.Columns[0].Name
+	}
+	return index, true, nil
+}
+
+// stripConstraintName removes a leading "CONSTRAINT [symbol]" clause and
+// returns the remaining definition and the symbol, if any.
+func stripConstraintName(def string) (string, string) {
+	loc := constraintRE.FindStringIndex(def)
+	if loc == nil {
+		return def, ""
+	}
+	symbol := strings.Trim(strings.TrimSpace(def[len("CONSTRAINT"):loc[1]]), "`")
+	// "CONSTRAINT PRIMARY KEY (...)" has no symbol; the optional group consumed a keyword.
+	switch strings.ToUpper(symbol) {
+	case "PRIMARY", "UNIQUE", "FOREIGN", "CHECK":
+		return strings.TrimSpace(def[len("CONSTRAINT"):]), ""
+	}
+	return def[loc[1]:], symbol
+}
+
+// matchingParen returns the index of the parenthesis closing the one at open,
+// skipping quoted text, or -1.
+func matchingParen(s string, open int) int {
+	level := 0
+	var quote byte
+	for i := open; i < len(s); i++ {
+		ch := s[i]
+		if quote != 0 {
+			if ch == '\\' && quote != '`' {
+				i++
+				continue
+			}
+			if ch == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch ch {
+		case '\'', '"', '`':
+			quote = ch
+		case '(':
+			level++
+		case ')':
+			level--
+			if level == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// ForeignKeys parses the FOREIGN KEY definitions among the table constraints.
+func (t *TableSchema) ForeignKeys() []ForeignKeySchema {
+	var keys []ForeignKeySchema
+	for _, def := range t.Constraints {
+		def, symbol := stripConstraintName(def)
+		matches := foreignKeyRE.FindStringSubmatch(def)
+		if matches == nil {
+			continue
+		}
+		refTable := matches[2]
+		if refTable == "" {
+			refTable = matches[3]
+		}
+		keys = append(keys, ForeignKeySchema{
+			Name:       symbol,
+			Columns:    splitIdentifierList(matches[1]),
+			RefTable:   refTable,
+			RefColumns: splitIdentifierList(matches[4]),
+		})
+	}
+	return keys
+}
+
+// splitIdentifierList splits a comma-separated list of possibly quoted
+// identifiers.
+func splitIdentifierList(list string) []string {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.Trim(strings.TrimSpace(name), "`"); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Column returns the column named name (case-insensitive).
+func (t *TableSchema) Column(name string) (ColumnSchema, bool) {
+	for _, column := range t.Columns {
+		if strings.EqualFold(column.Name, name) {
+			return column, true
+		}
+	}
+	return ColumnSchema{}, false
+}
+
+// CreateStatement renders the table as a MySQL CREATE TABLE statement.
+func (t *TableSchema) CreateStatement() string {
+	var defs []string
+	for _, column := range t.Columns {
+		defs = append(defs, QuoteIdentifier(column.Name)+" "+column.Definition)
+	}
+	for _, index := range t.Indexes {
+		defs = append(defs, index.Definition())
+	}
+	defs = append(defs, t.Constraints...)
+
+	var sb strings.Builder
+	sb.WriteString("CREATE TABLE " + QuoteIdentifier(t.Name) + " (\n  ")
+	sb.WriteString(strings.Join(defs, ",\n  "))
+	sb.WriteString("\n)")
+	if t.Options != "" {
+		sb.WriteString(" " + t.Options)
+	}
+	sb.WriteString(";")
+	return sb.String()
+}
+
+// Definition renders the index as it appears inside CREATE TABLE.
+func (i IndexSchema) Definition() string {
+	columns := make([]string, 0, len(i.Columns))
+	for _, column := range i.Columns {
+		text := QuoteIdentifier(column.Name)
+		if column.Length > 0 {
+			text += fmt.Sprintf("(%d)", column.Length)
+		}
+		columns = append(columns, text)
+	}
+	list := "(" + strings.Join(columns, ",") + ")"
+	switch i.Kind {
+	case IndexPrimary:
+		return "PRIMARY KEY " + list
+	case IndexUnique:
+		return "UNIQUE KEY " + QuoteIdentifier(i.Name) + " " + list
+	case IndexFulltext, IndexSpatial:
+		return i.Kind + " KEY " + QuoteIdentifier(i.Name) + " " + list
+	default:
+		return "KEY " + QuoteIdentifier(i.Name) + " " + list
+	}
+}
+
+// QuoteIdentifier quotes a MySQL identifier with backticks.
+func QuoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
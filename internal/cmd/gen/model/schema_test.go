@@ -0,0 +1,69 @@
+package model
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCreateTableCollectsColumnsIndexesAndOptions(t *testing.T) {
+	ddl := "CREATE TABLE `orders` (\n" +
+		"  `id` bigint unsigned NOT NULL AUTO_INCREMENT,\n" +
+		"  `user_id` bigint NOT NULL,\n" +
+		"  `title` varchar(255) NOT NULL DEFAULT '' COMMENT 'a, b (c)',\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  UNIQUE KEY `uk_user_title` (`user_id`,`title`(20)),\n" +
+		"  KEY `idx_title` USING BTREE (`title` DESC),\n" +
+		"  INDEX (user_id),\n" +
+		"  CONSTRAINT `fk_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;"
+
+	table, err := ParseCreateTable(ddl)
+	if err != nil {
+		t.Fatalf("ParseCreateTable() error = %v", err)
+	}
+	if table.Name != "orders" || table.Options != "ENGINE=InnoDB DEFAULT CHARSET=utf8mb4" {
+		t.Fatalf("table = %q, options = %q", table.Name, table.Options)
+	}
+	if len(table.Columns) != 3 || table.Columns[2].Definition != "varchar(255) NOT NULL DEFAULT '' COMMENT 'a, b (c)'" {
+		t.Fatalf("columns = %+v", table.Columns)
+	}
+	wantIndexes := []IndexSchema{
+		{Name: "PRIMARY", Kind: IndexPrimary, Columns: []IndexColumn{{Name: "id"}}},
+		{Name: "uk_user_title", Kind: IndexUnique, Columns: []IndexColumn{{Name: "user_id"}, {Name: "title", Length: 20}}},
+		{Name: "idx_title", Kind: IndexPlain, Columns: []IndexColumn{{Name: "title"}}},
+		{Name: "user_id", Kind: IndexPlain, Columns: []IndexColumn{{Name: "user_id"}}},
+	}
+	if !reflect.DeepEqual(table.Indexes, wantIndexes) {
+		t.Fatalf("indexes = %+v", table.Indexes)
+	}
+	if len(table.Constraints) != 1 || !strings.HasPrefix(table.Constraints[0], "CONSTRAINT `fk_user` FOREIGN KEY") {
+		t.Fatalf("constraints = %v", table.Constraints)
+	}
+	wantKeys := []ForeignKeySchema{{Name: "fk_user", Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"}}}
+	if !reflect.DeepEqual(table.ForeignKeys(), wantKeys) {
+		t.Fatalf("foreign keys = %+v", table.ForeignKeys())
+	}
+
+	rendered, err := ParseCreateTable(table.CreateStatement())
+	if err != nil {
+		t.Fatalf("re-parse CreateStatement() error = %v\n%s", err, table.CreateStatement())
+	}
+	if !reflect.DeepEqual(rendered, table) {
+		t.Fatalf("CreateStatement() did not round-trip:\n%s", table.CreateStatement())
+	}
+}
+
+func TestParseIndexDefinitionHandlesConstraintSymbols(t *testing.T) {
+	index, ok, err := parseIndexDefinition("CONSTRAINT PRIMARY KEY (`a`, `b`)")
+	if err != nil || !ok || index.Kind != IndexPrimary || len(index.Columns) != 2 {
+		t.Fatalf("primary key = %+v, %v, %v", index, ok, err)
+	}
+	index, ok, err = parseIndexDefinition("CONSTRAINT `uk_email` UNIQUE (`email`)")
+	if err != nil || !ok || index.Kind != IndexUnique || index.Name != "uk_email" {
+		t.Fatalf("unique = %+v, %v, %v", index, ok, err)
+	}
+	if _, ok, err := parseIndexDefinition("`key_name` varchar(10)"); ok || err != nil {
+		t.Fatalf("column parsed as index: %v, %v", ok, err)
+	}
+}
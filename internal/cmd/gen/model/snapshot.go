@@ -0,0 +1,116 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// schemaSnapshotVersion is the version of the schema snapshot format written by
+// gen model --snapshot.
+const schemaSnapshotVersion = 1
+
+// autoIncrementOptionRE matches the AUTO_INCREMENT counter of table options,
+// which changes with every insert and is left out of snapshots.
+var autoIncrementOptionRE = regexp.MustCompile(`(?i)\s*\bAUTO_INCREMENT\s*=\s*\d+`)
+
+// schemaSnapshot is the normalized schema saved by gen model --snapshot, from
+// which models can be generated without a database.
+type schemaSnapshot struct {
+	Version int `json:"version"`
+	// Prefix is the table prefix the models were generated with.
+	Prefix string          `json:"prefix,omitempty"`
+	Tables []snapshotTable `json:"tables"`
+}
+
+// snapshotTable is a table, or the columns of a view, of a schema snapshot.
+type snapshotTable struct {
+	*TableSchema
+	View bool `json:"view,omitempty"`
+}
+
+// newSchemaSnapshot normalizes definitions into a snapshot: tables are sorted
+// by name and their definitions rendered as ParseCreateTable reads them,
+// without AUTO_INCREMENT counters.
+func newSchemaSnapshot(definitions []tableDefinition, prefix string) (*schemaSnapshot, error) {
+	snapshot := &schemaSnapshot{Version: schemaSnapshotVersion, Prefix: prefix, Tables: make([]snapshotTable, 0, len(definitions))}
+	for _, definition := range definitions {
+		table, err := ParseCreateTable(definition.CreateTable)
+		if err != nil {
+			return nil, fmt.Errorf("parse CREATE TABLE statement: %w", err)
+		}
+		table.Options = strings.TrimSpace(autoIncrementOptionRE.ReplaceAllString(table.Options, ""))
+		snapshot.Tables = append(snapshot.Tables, snapshotTable{TableSchema: table, View: definition.View})
+	}
+	slices.SortFunc(snapshot.Tables, func(a, b snapshotTable) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return snapshot, nil
+}
+
+// definitions returns the tables and views of the snapshot.
+func (s *schemaSnapshot) definitions() []tableDefinition {
+	definitions := make([]tableDefinition, 0, len(s.Tables))
+	for _, table := range s.Tables {
+		definitions = append(definitions, tableDefinition{CreateTable: table.CreateStatement(), View: table.View})
+	}
+	return definitions
+}
+
+// writeSchemaSnapshot saves definitions to a snapshot file at path and returns
+// them in the normalized form they are read back in.
+func writeSchemaSnapshot(path string, definitions []tableDefinition, prefix string) ([]tableDefinition, error) {
+	snapshot, err := newSchemaSnapshot(definitions, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("build schema snapshot: %w", err)
+	}
+	content, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode schema snapshot: %w", err)
+	}
+	if err = os.WriteFile(path, append(content, '\n'), 0o644); err != nil {
+		return nil, fmt.Errorf("write schema snapshot %s: %w", path, err)
+	}
+	return snapshot.definitions(), nil
+}
+
+// readSchemaSnapshot reads the snapshot file at path.
+func readSchemaSnapshot(path string) (*schemaSnapshot, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read schema snapshot: %w", err)
+	}
+	var snapshot schemaSnapshot
+	if err = json.Unmarshal(content, &snapshot); err != nil {
+		return nil, fmt.Errorf("parse schema snapshot: %w", err)
+	}
+	if snapshot.Version != schemaSnapshotVersion {
+		return nil, fmt.Errorf("unsupported schema snapshot version %d", snapshot.Version)
+	}
+	for i, table := range snapshot.Tables {
+		if table.TableSchema == nil || table.Name == "" || len(table.Columns) == 0 {
+			return nil, fmt.Errorf("table %d of the schema snapshot has no name or columns", i+1)
+		}
+	}
+	return &snapshot, nil
+}
+
+// isSchemaSnapshot reports whether path is a JSON file with a list of tables,
+// which config files do not have.
+func isSchemaSnapshot(path string) bool {
+	if !strings.EqualFold(filepath.Ext(path), ".json") {
+		return false
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var probe struct {
+		Tables json.RawMessage `json:"tables"`
+	}
+	return json.Unmarshal(content, &probe) == nil && probe.Tables != nil
+}
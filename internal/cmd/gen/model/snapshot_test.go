@@ -0,0 +1,150 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSchemaSnapshotRoundTrips(t *testing.T) {
+	definitions := []tableDefinition{
+		{CreateTable: "CREATE TABLE `app_users` (\n  `id` bigint NOT NULL AUTO_INCREMENT,\n  `name` varchar(64) NOT NULL,\n  PRIMARY KEY (`id`),\n" +
+			"  KEY `idx_name` (`name`(10))\n) ENGINE=InnoDB AUTO_INCREMENT=42 DEFAULT CHARSET=utf8mb4;"},
+		{CreateTable: "CREATE TABLE `app_totals` (\n  `user_id` bigint NOT NULL\n);", View: true},
+	}
+	path := filepath.Join(t.TempDir(), "schema.lock.json")
+	written, err := writeSchemaSnapshot(path, definitions, "app_")
+	if err != nil {
+		t.Fatalf("writeSchemaSnapshot() error = %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "version": 1,
+  "prefix": "app_",
+  "tables": [
+    {
+      "name": "app_totals",
+      "columns": [
+        {
+          "name": "user_id",
+          "definition": "bigint NOT NULL"
+        }
+      ],
+      "view": true
+    },
+    {
+      "name": "app_users",
+      "columns": [
+        {
+          "name": "id",
+          "definition": "bigint NOT NULL AUTO_INCREMENT"
+        },
+        {
+          "name": "name",
+          "definition": "varchar(64) NOT NULL"
+        }
+      ],
+      "indexes": [
+        {
+          "name": "PRIMARY",
+          "kind": "PRIMARY",
+          "columns": [
+            {
+              "name": "id"
+            }
+          ]
+        },
+        {
+          "name": "idx_name",
+          "kind": "INDEX",
+          "columns": [
+            {
+              "name": "name",
+              "length": 10
+            }
+          ]
+        }
+      ],
+      "options": "ENGINE=InnoDB DEFAULT CHARSET=utf8mb4"
+    }
+  ]
+}
+`
+	if string(content) != want {
+		t.Fatalf("snapshot = %s", content)
+	}
+
+	src := tableSource{Paths: []string{path}}
+	if !src.isSnapshot() || src.isSQL() {
+		t.Fatal("snapshot file was not recognised")
+	}
+	read, err := readCreateTables(src)
+	if err != nil {
+		t.Fatalf("readCreateTables(snapshot) error = %v", err)
+	}
+	if !reflect.DeepEqual(read, written) || !read[0].View {
+		t.Fatalf("read %+v, wrote %+v", read, written)
+	}
+	if prefix := tablePrefix(src, ""); prefix != "app_" {
+		t.Fatalf("tablePrefix(snapshot) = %q", prefix)
+	}
+
+	// Writing the snapshot's own tables again changes nothing.
+	if _, err := writeSchemaSnapshot(path, read, "app_"); err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := os.ReadFile(path); string(again) != want {
+		t.Fatalf("rewritten snapshot = %s", again)
+	}
+}
+
+func TestReadSchemaSnapshotRejectsInvalidFiles(t *testing.T) {
+	dir := t.TempDir()
+	tests := map[string]string{
+		`{"version": 2, "tables": []}`:                   "unsupported schema snapshot version 2",
+		`{"version": 1, "tables": [{"name": "users"}]}`:  "table 1 of the schema snapshot has no name or columns",
+		`{"version": 1, "tables": [{"columns": "id"}]}`:  "parse schema snapshot",
+		`{"mysql": {"host": "db"}, "tables": "nothing"}`: "parse schema snapshot",
+	}
+	for content, want := range tests {
+		path := filepath.Join(dir, "schema.lock.json")
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := readSchemaSnapshot(path); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("readSchemaSnapshot(%s) error = %v, want %q", content, err, want)
+		}
+	}
+
+	config := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(config, []byte(`{"mysql": {"host": "db"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if isSchemaSnapshot(config) {
+		t.Fatal("isSchemaSnapshot() accepted a config file")
+	}
+}
+
+func TestModelSourceReadsFromDB(t *testing.T) {
+	dir := t.TempDir()
+	snapshot := filepath.Join(dir, "schema.lock.json")
+	if err := os.WriteFile(snapshot, []byte(`{"version": 1, "tables": []}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if paths, dsn, err := modelSource(nil, "", "config.yaml"); err != nil || len(paths) != 1 || paths[0] != "config.yaml" || dsn != "" {
+		t.Fatalf("modelSource(--from-db) = %q, %q, %v", paths, dsn, err)
+	}
+	if _, _, err := modelSource([]string{"schema.sql"}, "", "config.yaml"); err == nil || !strings.Contains(err.Error(), "not more than one") {
+		t.Fatalf("modelSource(file, --from-db) error = %v", err)
+	}
+	for _, fromDB := range []string{"schema.sql", snapshot} {
+		if _, _, err := modelSource(nil, "", fromDB); err == nil || !strings.Contains(err.Error(), "takes a config file") {
+			t.Fatalf("modelSource(--from-db %s) error = %v", fromDB, err)
+		}
+	}
+}
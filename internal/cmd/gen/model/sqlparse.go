@@ -3,11 +3,14 @@ package model
 import (
 	"fmt"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 	"unicode"
 	"unicode/utf8"
 
+	"github.com/jiajia556/godo/internal/service"
+	"github.com/jiajia556/godo/internal/template"
 	"github.com/jiajia556/godo/internal/utils"
 )
 
@@ -16,29 +19,126 @@ type fieldInfo struct {
 	typeName string
 	gormTags string
 	jsonTag  string
+	comment  string
+	// enum holds the allowed values of ENUM and SET columns.
+	enum *enumColumn
+	// column and sqlType are the column name and definition the field was
+	// parsed from; importPath is the package of a type set by the type map.
+	column     string
+	sqlType    string
+	importPath string
+	primaryKey bool
+	nullable   bool
+}
+
+// parsedTable is a CREATE TABLE statement reduced to what a model is built from.
+type parsedTable struct {
+	name    string
+	comment string
+	fields  []fieldInfo
+	// primaryKey lists the primary key columns in key order.
+	primaryKey []string
+}
+
+// StructOptions controls how a model struct is derived from a table.
+type StructOptions struct {
+	// Prefix is stripped from the table name when deriving the struct name.
+	Prefix string
+	// CommentTag adds the column comment as a gorm comment tag in addition to
+	// the field's doc comment.
+	CommentTag bool
+	// TypeMap overrides the built-in SQL to Go type mapping.
+	TypeMap service.TypeMap
+	// JSONCase is the naming strategy of JSON tags, one of the service.JSONCase
+	// constants; empty means snake.
+	JSONCase string
+	// JSONOmitEmpty adds omitempty to the JSON tags of nullable columns.
+	JSONOmitEmpty bool
+	// Timestamps names the creation, update and soft delete time columns.
+	Timestamps service.TimestampColumns
+	// ReadOnly marks every field read-only with the gorm "->" tag, as for
+	// the columns of a view.
+	ReadOnly bool
+	// associations holds the association fields planned for each table.
+	associations map[string][]fieldInfo
 }
 
 var createTableHeaderRE = regexp.MustCompile("(?i)^\\s*CREATE\\s+(?:TEMPORARY\\s+)?TABLE\\s+(?:IF\\s+NOT\\s+EXISTS\\s+)?(?:(?:`[^`]+`|[A-Za-z0-9_$]+)\\s*\\.\\s*)?(?:`([^`]+)`|([A-Za-z0-9_$]+))")
 
-// GenerateStruct generates Go struct definition from SQL create table statement
-func GenerateModelStruct(sql string) (string, string, string, error) {
-	tableName, fields, err := parseSQL(sql)
+// GenerateModelStruct generates Go struct definition from SQL create table statement.
+// The table prefix, if any, is stripped from the struct name; the returned table
+// name keeps it. Table and column comments become doc comments.
+func GenerateModelStruct(sql string, opts StructOptions) (string, string, string, error) {
+	model, err := buildModel(sql, opts)
 	if err != nil {
 		return "", "", "", err
 	}
+	return model.structText, model.structName, model.tableName, nil
+}
+
+// generatedModel is the Go code derived from a CREATE TABLE statement.
+type generatedModel struct {
+	structText string
+	structName string
+	tableName  string
+	imports    []string
+	enums      []template.EnumData
+	primaryKey []template.KeyField
+}
+
+func buildModel(sql string, opts StructOptions) (*generatedModel, error) {
+	table, err := parseSQL(sql)
+	if err != nil {
+		return nil, err
+	}
 
-	return buildStruct(tableName, fields), toCamelCase(tableName), utils.CamelToSnake(tableName), nil
+	if opts.CommentTag {
+		for i, f := range table.fields {
+			if f.comment != "" {
+				table.fields[i].gormTags += ";comment:" + commentTagValue(f.comment)
+			}
+		}
+	}
+	applyTypeMap(table, opts.TypeMap)
+	applyTimestampConventions(table.fields, opts.Timestamps)
+	if opts.ReadOnly {
+		markReadOnly(table.fields)
+	}
+	structName := modelStructName(table.name, opts.Prefix)
+	enums := applyEnumTypes(structName, table.fields)
+	primaryKey := primaryKeyFields(table)
+	table.fields = append(table.fields, opts.associations[table.name]...)
+	applyJSONTags(table.fields, opts.JSONCase, opts.JSONOmitEmpty)
+	return &generatedModel{
+		structText: buildStruct(structName, table.comment, table.fields),
+		structName: structName,
+		tableName:  table.name,
+		imports:    modelImports(table.fields),
+		enums:      enums,
+		primaryKey: primaryKey,
+	}, nil
 }
 
-func parseSQL(sql string) (string, []fieldInfo, error) {
+// modelStructName derives the struct name of a table, stripping prefix
+// (case-insensitively) unless nothing would be left of the name.
+func modelStructName(tableName, prefix string) string {
+	if prefix != "" && len(tableName) > len(prefix) && strings.EqualFold(tableName[:len(prefix)], prefix) {
+		if rest := strings.TrimLeft(tableName[len(prefix):], "_"); rest != "" {
+			tableName = rest
+		}
+	}
+	return toCamelCase(tableName)
+}
+
+func parseSQL(sql string) (*parsedTable, error) {
 	tableName, err := extractTableName(sql)
 	if err != nil {
-		return "", nil, err
+		return nil, err
 	}
 
-	fieldDefinitions, err := extractFieldDefinitions(sql)
+	fieldDefinitions, options, err := extractTableBody(sql)
 	if err != nil {
-		return "", nil, err
+		return nil, err
 	}
 
 	// Extract table-level primary key constraints like: PRIMARY KEY (`id`) or PRIMARY KEY (id, other_id)
@@ -53,15 +153,58 @@ func parseSQL(sql string) (string, []fieldInfo, error) {
 	for _, def := range fieldDefinitions {
 		fi, err := parseField(def, pkSet)
 		if err != nil {
-			return "", nil, err
+			return nil, err
 		}
 		if fi.name == "" {
 			continue
 		}
+		if _, ok := pkSet[strings.ToLower(fi.column)]; fi.primaryKey && !ok {
+			// Inline PRIMARY KEY on the column definition.
+			pkCols = append(pkCols, fi.column)
+		}
 		fields = append(fields, fi)
 	}
+	if err := applyIndexTags(fields, fieldDefinitions); err != nil {
+		return nil, fmt.Errorf("table %s: %w", tableName, err)
+	}
 
-	return tableName, fields, nil
+	return &parsedTable{name: tableName, comment: extractComment(options), fields: fields, primaryKey: pkCols}, nil
+}
+
+// applyIndexTags adds index and uniqueIndex gorm tags for the KEY, INDEX,
+// UNIQUE, FULLTEXT and SPATIAL definitions among defs so that tables created
+// from the model get the same indexes. Primary keys are tagged by parseField.
+func applyIndexTags(fields []fieldInfo, defs []string) error {
+	for _, def := range defs {
+		index, ok, err := parseIndexDefinition(def)
+		if err != nil {
+			return err
+		}
+		if !ok || index.Kind == IndexPrimary {
+			continue
+		}
+		for position, column := range index.Columns {
+			tag := "index:" + index.Name
+			if index.Kind == IndexUnique {
+				tag = "uniqueIndex:" + index.Name
+			}
+			if len(index.Columns) > 1 {
+				tag += fmt.Sprintf(",priority:%d", position+1)
+			}
+			if column.Length > 0 {
+				tag += fmt.Sprintf(",length:%d", column.Length)
+			}
+			if index.Kind == IndexFulltext || index.Kind == IndexSpatial {
+				tag += ",class:" + index.Kind
+			}
+			for i := range fields {
+				if strings.EqualFold(fields[i].column, column.Name) {
+					fields[i].gormTags += ";" + tag
+				}
+			}
+		}
+	}
+	return nil
 }
 
 // extractPrimaryKeyColumns returns column names declared in table-level PRIMARY KEY constraints.
@@ -110,9 +253,16 @@ func extractTableName(sql string) (string, error) {
 // It uses a simple state machine (paren nesting + quote tracking) so commas in
 // types, comments, or indexes won't break the split.
 func extractFieldDefinitions(sql string) ([]string, error) {
+	defs, _, err := extractTableBody(sql)
+	return defs, err
+}
+
+// extractTableBody returns the column/index definitions of a CREATE TABLE
+// statement together with the table options that follow the closing parenthesis.
+func extractTableBody(sql string) ([]string, string, error) {
 	header := createTableHeaderRE.FindStringIndex(sql)
 	if header == nil {
-		return nil, fmt.Errorf("CREATE TABLE header not found")
+		return nil, "", fmt.Errorf("CREATE TABLE header not found")
 	}
 	start := -1
 	level := 0
@@ -187,13 +337,14 @@ func extractFieldDefinitions(sql string) ([]string, error) {
 		}
 	}
 	if start == -1 {
-		return nil, fmt.Errorf("field definitions not found")
+		return nil, "", fmt.Errorf("field definitions not found")
 	}
 	if end == -1 || end <= start {
-		return nil, fmt.Errorf("field definitions not found (unmatched parentheses)")
+		return nil, "", fmt.Errorf("field definitions not found (unmatched parentheses)")
 	}
 
 	inner := sql[start+1 : end]
+	options := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(sql[end+1:]), ";"))
 
 	// Split by top-level commas (ignore commas inside parentheses/quotes).
 	defs := splitFieldDefinitions(inner)
@@ -205,7 +356,7 @@ func extractFieldDefinitions(sql string) ([]string, error) {
 			out = append(out, s)
 		}
 	}
-	return out, nil
+	return out, options, nil
 }
 
 // splitFieldDefinitions splits a column-definition block by top-level commas.
@@ -352,11 +503,22 @@ func parseField(def string, primaryKeyCols map[string]struct{}) (fieldInfo, erro
 		}
 	}
 
+	enum, err := parseEnumColumn(fieldName, typeInfo)
+	if err != nil {
+		return fieldInfo{}, err
+	}
+
 	return fieldInfo{
-		name:     toCamelCase(fieldName),
-		typeName: goType,
-		gormTags: buildGormTags(fieldName, tags),
-		jsonTag:  toSnakeCase(fieldName),
+		name:       toCamelCase(fieldName),
+		typeName:   goType,
+		gormTags:   buildGormTags(fieldName, tags),
+		jsonTag:    utils.CamelToSnake(fieldName),
+		comment:    extractComment(typeInfo),
+		enum:       enum,
+		column:     fieldName,
+		sqlType:    typeInfo,
+		primaryKey: tags["primaryKey"] == "true",
+		nullable:   tags["notNull"] != "true" && tags["primaryKey"] != "true",
 	}, nil
 }
 
@@ -446,7 +608,7 @@ func mapTypeAndTags(sqlType string) (string, map[string]string) {
 	case "char", "varchar", "tinytext", "text", "mediumtext", "longtext":
 		goType = "string"
 	case "enum", "set":
-		// Keep as string; application can define strong types if needed.
+		// Replaced by a named type per column, see applyEnumTypes.
 		goType = "string"
 	case "json":
 		// Most projects store json as string/[]byte; choose []byte to avoid encoding assumptions.
@@ -465,6 +627,19 @@ func mapTypeAndTags(sqlType string) (string, map[string]string) {
 	return goType, tags
 }
 
+// markReadOnly adds the gorm "->" tag, right after the column name, to the
+// fields that do not have it yet.
+func markReadOnly(fields []fieldInfo) {
+	for i, field := range fields {
+		parts := strings.Split(field.gormTags, ";")
+		if slices.Contains(parts, "->") {
+			continue
+		}
+		parts = slices.Insert(parts, 1, "->")
+		fields[i].gormTags = strings.Join(parts, ";")
+	}
+}
+
 func buildGormTags(fieldName string, tags map[string]string) string {
 	parts := []string{"column:" + fieldName}
 	keys := make([]string, 0, len(tags))
@@ -503,15 +678,17 @@ func capitalize(s string) string {
 	return string(unicode.ToUpper(r)) + s[size:]
 }
 
-func toSnakeCase(s string) string {
-	return strings.ToLower(s)
-}
-
-func buildStruct(tableName string, fields []fieldInfo) string {
+func buildStruct(structName, comment string, fields []fieldInfo) string {
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("type %s struct {\n", toCamelCase(tableName)))
+	if comment != "" {
+		writeDocComment(&sb, "", comment)
+	}
+	sb.WriteString(fmt.Sprintf("type %s struct {\n", structName))
 
 	for _, f := range fields {
+		if f.comment != "" {
+			writeDocComment(&sb, "    ", f.comment)
+		}
 		sb.WriteString(fmt.Sprintf("    %-8s %-16s `gorm:\"%s\" json:\"%s\"`\n",
 			f.name, f.typeName, f.gormTags, f.jsonTag))
 	}
@@ -519,3 +696,17 @@ func buildStruct(tableName string, fields []fieldInfo) string {
 	sb.WriteString("}")
 	return sb.String()
 }
+
+// primaryKeyFields returns the fields of the primary key columns in key order.
+func primaryKeyFields(table *parsedTable) []template.KeyField {
+	var keys []template.KeyField
+	for _, column := range table.primaryKey {
+		for _, f := range table.fields {
+			if strings.EqualFold(f.column, column) {
+				keys = append(keys, template.KeyField{Column: f.column, Field: f.name, Type: f.typeName})
+				break
+			}
+		}
+	}
+	return keys
+}
@@ -35,11 +35,11 @@ CREATE TABLE logs (
 	if len(statements) != 2 {
 		t.Fatalf("got %d CREATE TABLE statements, want 2: %v", len(statements), statements)
 	}
-	if !strings.Contains(statements[0], "ENGINE=InnoDB") {
-		t.Fatalf("first statement lost table options: %s", statements[0])
+	if !strings.Contains(statements[0].CreateTable, "ENGINE=InnoDB") {
+		t.Fatalf("first statement lost table options: %s", statements[0].CreateTable)
 	}
-	if !strings.Contains(statements[0], "contains;semicolon") {
-		t.Fatalf("first statement split at a quoted semicolon: %s", statements[0])
+	if !strings.Contains(statements[0].CreateTable, "contains;semicolon") {
+		t.Fatalf("first statement split at a quoted semicolon: %s", statements[0].CreateTable)
 	}
 }
 
@@ -51,7 +51,7 @@ func TestGenerateModelStructHandlesQualifiedTableAndConstraints(t *testing.T) {
 		"UNIQUE KEY `uk_name` (`display_name`)\n" +
 		") ENGINE=InnoDB;"
 
-	generated, structName, tableName, err := GenerateModelStruct(ddl)
+	generated, structName, tableName, err := GenerateModelStruct(ddl, StructOptions{})
 	if err != nil {
 		t.Fatalf("GenerateModelStruct() error = %v", err)
 	}
@@ -74,7 +74,7 @@ func TestGenerateModelStructHandlesQualifiedTableAndConstraints(t *testing.T) {
 
 func TestGenerateModelStructIgnoresHeaderCommentParentheses(t *testing.T) {
 	ddl := "CREATE TABLE users /* ignored ( comment ) */ (`id` bigint);"
-	generated, _, _, err := GenerateModelStruct(ddl)
+	generated, _, _, err := GenerateModelStruct(ddl, StructOptions{})
 	if err != nil {
 		t.Fatalf("GenerateModelStruct() error = %v", err)
 	}
@@ -84,10 +84,86 @@ func TestGenerateModelStructIgnoresHeaderCommentParentheses(t *testing.T) {
 }
 
 func TestSplitSQLStatementsRejectsUnterminatedInput(t *testing.T) {
-	if _, err := splitSQLStatements("CREATE TABLE users (name varchar(20) DEFAULT 'oops);"); err == nil {
-		t.Fatal("splitSQLStatements() accepted an unterminated quote")
+	if _, err := SplitSQLStatements("CREATE TABLE users (name varchar(20) DEFAULT 'oops);"); err == nil {
+		t.Fatal("SplitSQLStatements() accepted an unterminated quote")
 	}
-	if _, err := splitSQLStatements("/* unterminated"); err == nil {
-		t.Fatal("splitSQLStatements() accepted an unterminated block comment")
+	if _, err := SplitSQLStatements("/* unterminated"); err == nil {
+		t.Fatal("SplitSQLStatements() accepted an unterminated block comment")
+	}
+}
+
+func TestGenerateModelStructStripsTablePrefix(t *testing.T) {
+	ddl := "CREATE TABLE `app_user_profile` (`id` bigint NOT NULL, PRIMARY KEY (`id`));"
+	generated, structName, tableName, err := GenerateModelStruct(ddl, StructOptions{Prefix: "app_"})
+	if err != nil {
+		t.Fatalf("GenerateModelStruct() error = %v", err)
+	}
+	if structName != "UserProfile" || tableName != "app_user_profile" {
+		t.Fatalf("names = %q, %q; want UserProfile, app_user_profile", structName, tableName)
+	}
+	if !strings.Contains(generated, "type UserProfile struct") {
+		t.Fatalf("struct name kept the prefix:\n%s", generated)
+	}
+	if modelPackageName(structName) != "userprofile" {
+		t.Fatalf("package name = %q", modelPackageName(structName))
+	}
+}
+
+func TestGenerateModelStructKeepsTableNameVerbatim(t *testing.T) {
+	for _, table := range []string{"order__items", "audit-log", "OrderItems"} {
+		ddl := "CREATE TABLE `" + table + "` (`id` bigint NOT NULL, PRIMARY KEY (`id`));"
+		_, structName, tableName, err := GenerateModelStruct(ddl, StructOptions{})
+		if err != nil {
+			t.Fatalf("GenerateModelStruct(%s) error = %v", table, err)
+		}
+		if tableName != table {
+			t.Errorf("table name of %s (%s) = %q", table, structName, tableName)
+		}
+	}
+}
+
+func TestModelStructNameOnlyStripsMatchingPrefix(t *testing.T) {
+	tests := []struct{ table, prefix, want string }{
+		{"app_users", "app", "Users"},
+		{"APP_users", "app_", "Users"},
+		{"logs", "app_", "Logs"},
+		{"app_", "app_", "App"},
+		{"users", "", "Users"},
+	}
+	for _, tt := range tests {
+		if got := modelStructName(tt.table, tt.prefix); got != tt.want {
+			t.Errorf("modelStructName(%q, %q) = %q, want %q", tt.table, tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateModelStructEmitsIndexTags(t *testing.T) {
+	ddl := "CREATE TABLE `posts` (\n" +
+		"`id` bigint NOT NULL,\n" +
+		"`user_id` bigint NOT NULL,\n" +
+		"`slug` varchar(191) NOT NULL,\n" +
+		"`title` varchar(255) NOT NULL,\n" +
+		"`body` text,\n" +
+		"PRIMARY KEY (`id`),\n" +
+		"UNIQUE KEY `uk_user_slug` (`user_id`, `slug`),\n" +
+		"KEY `idx_title` (`title`(32)),\n" +
+		"INDEX (`user_id`),\n" +
+		"FULLTEXT KEY `ft_body` (`body`)\n" +
+		");"
+
+	generated, _, _, err := GenerateModelStruct(ddl, StructOptions{})
+	if err != nil {
+		t.Fatalf("GenerateModelStruct() error = %v", err)
+	}
+	for _, want := range []string{
+		`column:user_id;notNull;uniqueIndex:uk_user_slug,priority:1;index:user_id"`,
+		`column:slug;notNull;uniqueIndex:uk_user_slug,priority:2"`,
+		`column:title;notNull;index:idx_title,length:32"`,
+		`column:body;index:ft_body,class:FULLTEXT"`,
+		`column:id;notNull;primaryKey"`,
+	} {
+		if !strings.Contains(generated, want) {
+			t.Errorf("generated struct does not contain %s:\n%s", want, generated)
+		}
 	}
 }
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"strings"
+
+	"github.com/jiajia556/godo/internal/service"
+)
+
+// Types of soft delete columns: gorm.DeletedAt for time columns and the
+// soft_delete plugin's unix timestamp for integer columns.
+const (
+	softDeleteTimeType = "gorm.DeletedAt"
+	softDeleteUnixType = "soft_delete.DeletedAt"
+)
+
+var softDeleteImports = map[string]string{
+	softDeleteTimeType: "gorm.io/gorm",
+	softDeleteUnixType: "gorm.io/plugin/soft_delete",
+}
+
+// applyTimestampConventions marks the creation and update time columns named
+// in columns with autoCreateTime and autoUpdateTime, and gives soft delete
+// columns a DeletedAt type. Columns of any other type, for example one set by
+// the type map, are left alone.
+func applyTimestampConventions(fields []fieldInfo, columns service.TimestampColumns) {
+	for i := range fields {
+		f := &fields[i]
+		if f.column == "" {
+			continue
+		}
+		isTime, isUnix := f.typeName == "time.Time", isUnixTimeType(f.typeName)
+		if !isTime && !isUnix {
+			continue
+		}
+		switch {
+		case containsFold(columns.CreatedAt, f.column):
+			f.gormTags += ";" + timestampTag("autoCreateTime", isUnix, columns.UnixPrecision)
+		case containsFold(columns.UpdatedAt, f.column):
+			f.gormTags += ";" + timestampTag("autoUpdateTime", isUnix, columns.UnixPrecision)
+		case containsFold(columns.DeletedAt, f.column):
+			f.typeName = softDeleteTimeType
+			if isUnix {
+				f.typeName = softDeleteUnixType
+				if columns.UnixPrecision != "" {
+					f.gormTags += ";softDelete:" + columns.UnixPrecision
+				}
+			}
+			f.importPath = softDeleteImports[f.typeName]
+		}
+	}
+}
+
+// timestampTag returns an autoCreateTime or autoUpdateTime tag. Integer
+// columns hold unix seconds unless precision is milli or nano.
+func timestampTag(name string, isUnix bool, precision string) string {
+	if isUnix && precision != "" {
+		return name + ":" + precision
+	}
+	return name
+}
+
+// isUnixTimeType reports whether typeName is the Go type of an INT or BIGINT
+// column.
+func isUnixTimeType(typeName string) bool {
+	switch typeName {
+	case "int32", "uint32", "int64", "uint64":
+		return true
+	}
+	return false
+}
+
+func containsFold(names []string, name string) bool {
+	for _, candidate := range names {
+		if strings.EqualFold(candidate, name) {
+			return true
+		}
+	}
+	return false
+}
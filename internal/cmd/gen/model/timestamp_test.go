@@ -0,0 +1,71 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jiajia556/godo/internal/service"
+)
+
+func TestTimestampConventions(t *testing.T) {
+	sql := "CREATE TABLE `posts` (" +
+		"`id` bigint unsigned NOT NULL, " +
+		"`created_at` datetime NOT NULL, " +
+		"`updated_at` datetime NOT NULL, " +
+		"`deleted_at` datetime DEFAULT NULL, " +
+		"`created` int NOT NULL, " +
+		"`modified` bigint NOT NULL, " +
+		"`removed` bigint unsigned NOT NULL DEFAULT 0, " +
+		"`published_at` datetime, " +
+		"PRIMARY KEY (`id`));"
+	defaults := service.TimestampColumns{
+		CreatedAt: []string{"created_at", "created"},
+		UpdatedAt: []string{"updated_at", "modified"},
+		DeletedAt: []string{"deleted_at"},
+	}
+	model, err := buildModel(sql, StructOptions{Timestamps: defaults})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"column:created_at;notNull;autoCreateTime\"",
+		"column:updated_at;notNull;autoUpdateTime\"",
+		"DeletedAt gorm.DeletedAt",
+		"column:created;notNull;autoCreateTime\"",
+		"column:modified;notNull;autoUpdateTime\"",
+		"Removed  uint64",
+	} {
+		if !strings.Contains(model.structText, want) {
+			t.Errorf("struct does not contain %q:\n%s", want, model.structText)
+		}
+	}
+	if strings.Contains(model.structText, "column:published_at;auto") {
+		t.Errorf("unconfigured column got a timestamp tag:\n%s", model.structText)
+	}
+	if strings.Join(model.imports, ",") != "gorm.io/gorm,time" {
+		t.Errorf("imports = %v", model.imports)
+	}
+
+	unix := service.TimestampColumns{
+		CreatedAt:     []string{"created"},
+		DeletedAt:     []string{"removed"},
+		UnixPrecision: "milli",
+	}
+	model, err = buildModel(sql, StructOptions{Timestamps: unix})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"column:created;notNull;autoCreateTime:milli\"",
+		"Removed  soft_delete.DeletedAt",
+		"softDelete:milli",
+		"DeletedAt time.Time",
+	} {
+		if !strings.Contains(model.structText, want) {
+			t.Errorf("struct does not contain %q:\n%s", want, model.structText)
+		}
+	}
+	if strings.Join(model.imports, ",") != "gorm.io/plugin/soft_delete,time" {
+		t.Errorf("imports = %v", model.imports)
+	}
+}
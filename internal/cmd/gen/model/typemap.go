@@ -0,0 +1,123 @@
+package model
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jiajia556/godo/internal/service"
+)
+
+// builtinImports lists the packages of the qualified types chosen by
+// mapTypeAndTags.
+var builtinImports = map[string]string{
+	"time.Time":       "time",
+	"decimal.Decimal": "github.com/shopspring/decimal",
+}
+
+var (
+	sqlTypeRE      = regexp.MustCompile(`^(\w+)(\([^)]*\))?( unsigned\b)?`)
+	sqlTypeSpaceRE = regexp.MustCompile(` ?([(,]) ?| (\))`)
+)
+
+// applyTypeMap replaces the Go types of the fields matched by typeMap. A mapped
+// ENUM or SET column gets the mapped type instead of a generated enum type.
+func applyTypeMap(table *parsedTable, typeMap service.TypeMap) {
+	for i := range table.fields {
+		f := &table.fields[i]
+		goType, ok := lookupTypeMap(typeMap, table.name, f.column, f.sqlType)
+		if !ok {
+			continue
+		}
+		f.typeName, f.importPath, f.enum = goType.Type, goType.Import, nil
+	}
+}
+
+// lookupTypeMap finds the mapping of a column by table.column, then by column
+// name pattern, then by SQL type.
+func lookupTypeMap(typeMap service.TypeMap, table, column, sqlType string) (service.GoType, bool) {
+	for key, goType := range typeMap.Columns {
+		if strings.EqualFold(key, table+"."+column) {
+			return goType, true
+		}
+	}
+
+	patterns := make([]string, 0, len(typeMap.ColumnPatterns))
+	for pattern := range typeMap.ColumnPatterns {
+		patterns = append(patterns, pattern)
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i]) != len(patterns[j]) {
+			return len(patterns[i]) > len(patterns[j])
+		}
+		return patterns[i] < patterns[j]
+	})
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(column)); ok {
+			return typeMap.ColumnPatterns[pattern], true
+		}
+	}
+
+	if len(typeMap.SQLTypes) == 0 {
+		return service.GoType{}, false
+	}
+	bySQLType := make(map[string]service.GoType, len(typeMap.SQLTypes))
+	for key, goType := range typeMap.SQLTypes {
+		bySQLType[normalizeSQLType(key)] = goType
+	}
+	for _, candidate := range sqlTypeCandidates(sqlType) {
+		if goType, ok := bySQLType[candidate]; ok {
+			return goType, true
+		}
+	}
+	return service.GoType{}, false
+}
+
+// sqlTypeCandidates returns the keys a column type is looked up by, from the
+// most to the least specific: "bigint(20) unsigned", "bigint(20)",
+// "bigint unsigned" and "bigint".
+func sqlTypeCandidates(sqlType string) []string {
+	matches := sqlTypeRE.FindStringSubmatch(normalizeSQLType(sqlType))
+	if matches == nil {
+		return nil
+	}
+	base, params, unsigned := matches[1], matches[2], matches[3] != ""
+	var candidates []string
+	add := func(candidate string, ok bool) {
+		if ok {
+			candidates = append(candidates, candidate)
+		}
+	}
+	add(base+params+" unsigned", params != "" && unsigned)
+	add(base+params, params != "")
+	add(base+" unsigned", unsigned)
+	add(base, true)
+	return candidates
+}
+
+// normalizeSQLType lowercases a SQL type, collapses whitespace and drops the
+// spaces around its parentheses and commas, so "DECIMAL (10, 2)" becomes
+// "decimal(10,2)".
+func normalizeSQLType(sqlType string) string {
+	s := strings.Join(strings.Fields(strings.ToLower(sqlType)), " ")
+	return sqlTypeSpaceRE.ReplaceAllString(s, "$1$2")
+}
+
+// modelImports returns the sorted import paths needed by the struct fields.
+func modelImports(fields []fieldInfo) []string {
+	seen := make(map[string]bool)
+	var imports []string
+	for _, f := range fields {
+		importPath := f.importPath
+		if importPath == "" {
+			importPath = builtinImports[strings.TrimLeft(f.typeName, "*[]")]
+		}
+		if importPath != "" && !seen[importPath] {
+			seen[importPath] = true
+			imports = append(imports, importPath)
+		}
+	}
+	sort.Strings(imports)
+	return imports
+}
@@ -0,0 +1,94 @@
+package model
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jiajia556/godo/internal/service"
+)
+
+func TestSQLTypeCandidatesFromMostSpecific(t *testing.T) {
+	got := sqlTypeCandidates("BIGINT (20) UNSIGNED NOT NULL")
+	want := []string{"bigint(20) unsigned", "bigint(20)", "bigint unsigned", "bigint"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("sqlTypeCandidates() = %v, want %v", got, want)
+	}
+	if got := sqlTypeCandidates("json DEFAULT NULL"); !reflect.DeepEqual(got, []string{"json"}) {
+		t.Fatalf("sqlTypeCandidates(json) = %v", got)
+	}
+	if got := normalizeSQLType("DECIMAL ( 10 , 2 )"); got != "decimal(10,2)" {
+		t.Fatalf("normalizeSQLType() = %q", got)
+	}
+}
+
+func TestBuildModelAppliesTypeMapByPrecedence(t *testing.T) {
+	ddl := "CREATE TABLE `users` (" +
+		"`id` bigint NOT NULL, " +
+		"`active` tinyint(1) NOT NULL, " +
+		"`deleted` tinyint(1), " +
+		"`profile` json, " +
+		"`owner_uuid` char(36), " +
+		"`state` enum('a','b'), " +
+		"`created_at` datetime, " +
+		"PRIMARY KEY (`id`));"
+	typeMap := service.TypeMap{
+		Columns: map[string]service.GoType{
+			"USERS.deleted": {Type: "int8"},
+			"users.state":   {Type: "string"},
+		},
+		ColumnPatterns: map[string]service.GoType{
+			"*_uuid": {Type: "uuid.UUID", Import: "github.com/google/uuid"},
+			"*":      {Type: "should.NotMatch", Import: "example.com/never"},
+		},
+		SQLTypes: map[string]service.GoType{
+			"tinyint(1)": {Type: "bool"},
+			"JSON":       {Type: "datatypes.JSON", Import: "gorm.io/datatypes"},
+		},
+	}
+	// The catch-all pattern would shadow the SQL type entries; drop it after
+	// checking that longer patterns win.
+	model, err := buildModel(ddl, StructOptions{TypeMap: typeMap})
+	if err != nil {
+		t.Fatalf("buildModel() error = %v", err)
+	}
+	if !strings.Contains(model.structText, "uuid.UUID") || strings.Contains(model.structText, "OwnerUuid should.NotMatch") {
+		t.Fatalf("longer pattern did not win:\n%s", model.structText)
+	}
+	delete(typeMap.ColumnPatterns, "*")
+
+	model, err = buildModel(ddl, StructOptions{TypeMap: typeMap})
+	if err != nil {
+		t.Fatalf("buildModel() error = %v", err)
+	}
+	for _, want := range []string{
+		"Active    bool",
+		"Deleted   int8",
+		"Profile   datatypes.JSON",
+		"OwnerUuid uuid.UUID",
+		"State     string",
+		"CreatedAt time.Time",
+	} {
+		fields := strings.Fields(want)
+		if !containsField(model.structText, fields[0], fields[1]) {
+			t.Errorf("struct does not declare %s:\n%s", want, model.structText)
+		}
+	}
+	if len(model.enums) != 0 {
+		t.Fatalf("mapped enum column still generated a type: %+v", model.enums)
+	}
+	wantImports := []string{"github.com/google/uuid", "gorm.io/datatypes", "time"}
+	if !reflect.DeepEqual(model.imports, wantImports) {
+		t.Fatalf("imports = %v, want %v", model.imports, wantImports)
+	}
+}
+
+func containsField(structText, name, typeName string) bool {
+	for _, line := range strings.Split(structText, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == name && fields[1] == typeName {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,303 @@
+package model
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jiajia556/godo/internal/template"
+	"github.com/jiajia556/godo/internal/utils"
+)
+
+// generatedModelMethods lists the methods in model.go that are owned by the
+// generator and replaced during an update. Any other method is user code.
+var generatedModelMethods = []string{"ID", "PrimaryKeyColumns", "PrimaryKey", "Key", "TableName", "GetCreateDDL", "IsView"}
+
+// fieldChanges summarizes how a model struct changed during an update.
+type fieldChanges struct {
+	added   []string
+	removed []string
+	changed []string
+}
+
+func (c fieldChanges) empty() bool {
+	return len(c.added) == 0 && len(c.removed) == 0 && len(c.changed) == 0
+}
+
+func (c fieldChanges) String() string {
+	if c.empty() {
+		return "no field changes"
+	}
+	var parts []string
+	if len(c.added) > 0 {
+		parts = append(parts, "added: "+strings.Join(c.added, ", "))
+	}
+	if len(c.removed) > 0 {
+		parts = append(parts, "removed: "+strings.Join(c.removed, ", "))
+	}
+	if len(c.changed) > 0 {
+		parts = append(parts, "changed: "+strings.Join(c.changed, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// replacement describes a byte range of the existing file to be replaced.
+type replacement struct {
+	start, end int
+	text       string
+}
+
+// updateModelFile rewrites the generated declarations in an existing model.go:
+// the model struct, its composite key struct and the generatedModelMethods.
+// Generated declarations the template no longer produces are removed.
+// Declarations are located via the Go AST so that user-added methods, comments
+// and imports are preserved.
+func updateModelFile(path string, data template.ModelData, modelTmpl string) (fieldChanges, error) {
+	rendered, err := template.Render(modelTmpl, data)
+	if err != nil {
+		return fieldChanges{}, fmt.Errorf("render model template: %w", err)
+	}
+	existing, err := os.ReadFile(path)
+	if err != nil {
+		return fieldChanges{}, fmt.Errorf("read model file: %w", err)
+	}
+
+	fset := token.NewFileSet()
+	newFile, err := parser.ParseFile(fset, "generated.go", rendered, parser.ParseComments)
+	if err != nil {
+		return fieldChanges{}, fmt.Errorf("parse generated model: %w", err)
+	}
+	oldFile, err := parser.ParseFile(fset, path, existing, parser.ParseComments)
+	if err != nil {
+		return fieldChanges{}, fmt.Errorf("parse model file %s: %w", path, err)
+	}
+
+	newStruct, newStructRange := findStructDecl(fset, newFile, data.ModelStructName)
+	if newStruct == nil {
+		return fieldChanges{}, fmt.Errorf("generated model does not declare struct %s", data.ModelStructName)
+	}
+	oldStruct, oldStructRange := findStructDecl(fset, oldFile, data.ModelStructName)
+	if oldStruct == nil {
+		return fieldChanges{}, fmt.Errorf("struct %s not found in %s", data.ModelStructName, path)
+	}
+	changes := compareStructFields(oldStruct, newStruct)
+
+	replacements := []replacement{{
+		start: oldStructRange[0],
+		end:   oldStructRange[1],
+		text:  string(rendered[newStructRange[0]:newStructRange[1]]),
+	}}
+	var appended []string
+	keyStructName := data.ModelStructName + "Key"
+	newKey, newKeyRange := findStructDecl(fset, newFile, keyStructName)
+	oldKey, oldKeyRange := findStructDecl(fset, oldFile, keyStructName)
+	switch {
+	case newKey != nil && oldKey != nil:
+		replacements = append(replacements, replacement{start: oldKeyRange[0], end: oldKeyRange[1], text: string(rendered[newKeyRange[0]:newKeyRange[1]])})
+	case newKey != nil:
+		appended = append(appended, string(rendered[newKeyRange[0]:newKeyRange[1]]))
+	case oldKey != nil:
+		replacements = append(replacements, replacement{start: oldKeyRange[0], end: oldKeyRange[1]})
+	}
+	for _, name := range generatedModelMethods {
+		newRange, inNew := findMethodDecl(fset, newFile, data.ModelStructName, name)
+		oldRange, inOld := findMethodDecl(fset, oldFile, data.ModelStructName, name)
+		switch {
+		case inNew && inOld:
+			replacements = append(replacements, replacement{start: oldRange[0], end: oldRange[1], text: string(rendered[newRange[0]:newRange[1]])})
+		case inNew:
+			appended = append(appended, string(rendered[newRange[0]:newRange[1]]))
+		case inOld:
+			replacements = append(replacements, replacement{start: oldRange[0], end: oldRange[1]})
+		}
+	}
+	if imports := missingImports(oldFile, newFile); len(imports) > 0 {
+		replacements = append(replacements, importReplacement(fset, oldFile, imports))
+	}
+
+	content := applyReplacements(existing, replacements)
+	for _, text := range appended {
+		content = append(bytes.TrimRight(content, "\n"), []byte("\n\n"+text+"\n")...)
+	}
+	formatted, err := format.Source(content)
+	if err != nil {
+		return fieldChanges{}, fmt.Errorf("format updated model %s: %w", path, err)
+	}
+	if !bytes.Equal(formatted, existing) {
+		if err := utils.WriteFile(path, string(formatted)); err != nil {
+			return fieldChanges{}, fmt.Errorf("write model file %s: %w", path, err)
+		}
+	}
+	return changes, nil
+}
+
+// findStructDecl returns the struct type named name and the byte range to replace.
+// A declaration holding only this type is replaced with its doc comment; a grouped
+// declaration only has the single type spec replaced.
+func findStructDecl(fset *token.FileSet, file *ast.File, name string) (*ast.StructType, [2]int) {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			structType, ok := typeSpec.Type.(*ast.StructType)
+			if !ok || typeSpec.Name.Name != name {
+				continue
+			}
+			if len(gen.Specs) == 1 && gen.Lparen == token.NoPos {
+				return structType, nodeRange(fset, gen.Doc, gen)
+			}
+			return structType, nodeRange(fset, typeSpec.Doc, typeSpec)
+		}
+	}
+	return nil, [2]int{}
+}
+
+// findMethodDecl returns the byte range (including doc comment) of the method
+// name declared on receiver type recvName.
+func findMethodDecl(fset *token.FileSet, file *ast.File, recvName, name string) ([2]int, bool) {
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil || fn.Name.Name != name {
+			continue
+		}
+		if receiverName(fn.Recv) == recvName {
+			return nodeRange(fset, fn.Doc, fn), true
+		}
+	}
+	return [2]int{}, false
+}
+
+func receiverName(recv *ast.FieldList) string {
+	if len(recv.List) == 0 {
+		return ""
+	}
+	expr := recv.List[0].Type
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+func nodeRange(fset *token.FileSet, doc *ast.CommentGroup, node ast.Node) [2]int {
+	start := node.Pos()
+	if doc != nil {
+		start = doc.Pos()
+	}
+	return [2]int{fset.Position(start).Offset, fset.Position(node.End()).Offset}
+}
+
+// compareStructFields reports fields added, removed, or changed (type or tag)
+// between two struct definitions, keyed by field name.
+func compareStructFields(oldStruct, newStruct *ast.StructType) fieldChanges {
+	oldFields := structFieldSignatures(oldStruct)
+	newFields := structFieldSignatures(newStruct)
+
+	var changes fieldChanges
+	for name, signature := range newFields {
+		oldSignature, ok := oldFields[name]
+		switch {
+		case !ok:
+			changes.added = append(changes.added, name)
+		case oldSignature != signature:
+			changes.changed = append(changes.changed, name)
+		}
+	}
+	for name := range oldFields {
+		if _, ok := newFields[name]; !ok {
+			changes.removed = append(changes.removed, name)
+		}
+	}
+	sort.Strings(changes.added)
+	sort.Strings(changes.removed)
+	sort.Strings(changes.changed)
+	return changes
+}
+
+func structFieldSignatures(structType *ast.StructType) map[string]string {
+	fields := make(map[string]string)
+	for _, field := range structType.Fields.List {
+		signature := types.ExprString(field.Type)
+		if field.Tag != nil {
+			signature += " " + field.Tag.Value
+		}
+		if len(field.Names) == 0 {
+			fields[types.ExprString(field.Type)] = signature
+			continue
+		}
+		for _, name := range field.Names {
+			fields[name.Name] = signature
+		}
+	}
+	return fields
+}
+
+// missingImports returns import specs of the generated file that the existing
+// file lacks. Unused imports are removed later by goimports.
+func missingImports(oldFile, newFile *ast.File) []string {
+	existing := make(map[string]struct{}, len(oldFile.Imports))
+	for _, spec := range oldFile.Imports {
+		existing[spec.Path.Value] = struct{}{}
+	}
+	var missing []string
+	for _, spec := range newFile.Imports {
+		if _, ok := existing[spec.Path.Value]; ok {
+			continue
+		}
+		line := spec.Path.Value
+		if spec.Name != nil {
+			line = spec.Name.Name + " " + line
+		}
+		missing = append(missing, line)
+	}
+	return missing
+}
+
+// importReplacement inserts import lines into the first import declaration of
+// file, or adds a new declaration after the package clause.
+func importReplacement(fset *token.FileSet, file *ast.File, imports []string) replacement {
+	block := "\n\t" + strings.Join(imports, "\n\t")
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		if gen.Lparen.IsValid() {
+			offset := fset.Position(gen.Rparen).Offset
+			return replacement{start: offset, end: offset, text: block + "\n"}
+		}
+		start := fset.Position(gen.Pos()).Offset
+		end := fset.Position(gen.End()).Offset
+		spec := gen.Specs[0].(*ast.ImportSpec)
+		line := spec.Path.Value
+		if spec.Name != nil {
+			line = spec.Name.Name + " " + line
+		}
+		return replacement{start: start, end: end, text: "import (\n\t" + line + block + "\n)"}
+	}
+	offset := fset.Position(file.Name.End()).Offset
+	return replacement{start: offset, end: offset, text: "\n\nimport (" + block + "\n)"}
+}
+
+func applyReplacements(content []byte, replacements []replacement) []byte {
+	sort.Slice(replacements, func(i, j int) bool {
+		return replacements[i].start > replacements[j].start
+	})
+	out := append([]byte(nil), content...)
+	for _, r := range replacements {
+		out = append(out[:r.start], append([]byte(r.text), out[r.end:]...)...)
+	}
+	return out
+}
@@ -0,0 +1,210 @@
+package model
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jiajia556/godo/internal/template"
+	"github.com/jiajia556/godo/templates"
+)
+
+func TestUpdateModelFilePreservesCustomCode(t *testing.T) {
+	modelTemplate, err := templates.TemplateFS.ReadFile("default/internal/common/models/model.go.templ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	modelData := func(ddl string) template.ModelData {
+		model, err := buildModel(ddl, StructOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return modelTemplateData(model, "example.com/project", ddl)
+	}
+
+	modelPath := filepath.Join(t.TempDir(), "model.go")
+	before := "CREATE TABLE `users` (`id` bigint unsigned NOT NULL, `name` varchar(64) NOT NULL, `age` int, PRIMARY KEY (`id`));"
+	if err := template.CreateFile(string(modelTemplate), modelData(before), modelPath); err != nil {
+		t.Fatal(err)
+	}
+	original, err := os.ReadFile(modelPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	custom := string(original) + "\n\n// DisplayName is maintained by hand.\nfunc (data *Users) DisplayName() string {\n\treturn data.Name\n}\n"
+	if err := os.WriteFile(modelPath, []byte(custom), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	after := "CREATE TABLE `users` (`id` bigint unsigned NOT NULL, `name` varchar(128) NOT NULL, `created_at` datetime, PRIMARY KEY (`id`));"
+	changes, err := updateModelFile(modelPath, modelData(after), string(modelTemplate))
+	if err != nil {
+		t.Fatalf("updateModelFile() error = %v", err)
+	}
+	if got := changes.String(); got != "added: CreatedAt; removed: Age" {
+		t.Fatalf("changes = %q", got)
+	}
+	updated, err := os.ReadFile(modelPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(updated)
+	for _, expected := range []string{"CreatedAt", "// DisplayName is maintained by hand.", "func (data *Users) DisplayName() string", "varchar(128)", `"time"`} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("updated model does not contain %q:\n%s", expected, text)
+		}
+	}
+	if strings.Contains(text, "Age ") {
+		t.Fatalf("removed column is still present:\n%s", text)
+	}
+	if strings.Count(text, "func (data *Users) TableName()") != 1 {
+		t.Fatalf("TableName was duplicated:\n%s", text)
+	}
+}
+
+func TestCompareStructFieldsReportsChanges(t *testing.T) {
+	parse := func(src string) *ast.StructType {
+		file, err := parser.ParseFile(token.NewFileSet(), "t.go", "package p\n\n"+src, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		structType, _ := findStructDecl(token.NewFileSet(), file, "T")
+		return structType
+	}
+	oldStruct := parse("type T struct {\n\tA int\n\tB string\n\tC int `json:\"c\"`\n}")
+	newStruct := parse("type T struct {\n\tA int\n\tC int `json:\"cc\"`\n\tD bool\n}")
+	changes := compareStructFields(oldStruct, newStruct)
+	if got := changes.String(); got != "added: D; removed: B; changed: C" {
+		t.Fatalf("changes = %q", got)
+	}
+	if (fieldChanges{}).String() != "no field changes" {
+		t.Fatal("empty changes summary is wrong")
+	}
+}
+
+func TestUpdateModelFileRequiresExistingStruct(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "model.go")
+	if err := os.WriteFile(path, []byte("package users\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := updateModelFile(path, template.ModelData{ModelStructName: "Users", ModelStruct: "type Users struct {\n\tId uint64\n}"}, "package users\n\n{{.ModelStruct}}\n")
+	if err == nil || !strings.Contains(err.Error(), "struct Users not found") {
+		t.Fatalf("updateModelFile() error = %v", err)
+	}
+}
+
+func TestModelTemplateMatchesPrimaryKey(t *testing.T) {
+	modelTemplate, err := templates.TemplateFS.ReadFile("default/internal/common/models/model.go.templ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	render := func(ddl string) string {
+		t.Helper()
+		model, err := buildModel(ddl, StructOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		rendered, err := template.Render(string(modelTemplate), modelTemplateData(model, "example.com/project", ddl))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := parser.ParseFile(token.NewFileSet(), "model.go", rendered, 0); err != nil {
+			t.Fatalf("rendered model does not parse: %v\n%s", err, rendered)
+		}
+		return string(rendered)
+	}
+
+	tests := []struct {
+		ddl      string
+		want     []string
+		unwanted []string
+	}{
+		{
+			ddl:      "CREATE TABLE `users` (`id` bigint unsigned NOT NULL, PRIMARY KEY (`id`));",
+			want:     []string{"}\n\nfunc (data *Users) ID() uint64 {\n\treturn data.Id\n", `return []string{"id"}`},
+			unwanted: []string{") Key()", "placeholder"},
+		},
+		{
+			ddl:  "CREATE TABLE `profiles` (`user_id` int NOT NULL PRIMARY KEY, `bio` text);",
+			want: []string{"return uint64(data.UserId)", `return []any{data.UserId}`},
+		},
+		{
+			ddl: "CREATE TABLE `countries` (`code` char(2) NOT NULL, `name` varchar(64), PRIMARY KEY (`code`));",
+			want: []string{
+				"// ID is a placeholder required by mysqlx.Model and returns 0 for every record:\n// the primary key of Countries is not an integer. Use PrimaryKey() to\n// identify records.\nfunc (data *Countries) ID() uint64 {\n\treturn 0\n}",
+				`return []string{"code"}`,
+			},
+		},
+		{
+			ddl: "CREATE TABLE `user_roles` (`user_id` bigint NOT NULL, `role` varchar(16) NOT NULL, PRIMARY KEY (`user_id`, `role`));",
+			want: []string{
+				"type UserRolesKey struct {\n\tUserId int64\n\tRole string\n}",
+				"func (data *UserRoles) Key() UserRolesKey {\n\treturn UserRolesKey{UserId: data.UserId, Role: data.Role}",
+				`return []string{"user_id", "role"}`,
+				"// the primary key of UserRoles spans several columns. Use Key() or\n// PrimaryKey() to identify records.\nfunc (data *UserRoles) ID() uint64 {\n\treturn 0\n}",
+			},
+		},
+		{
+			ddl:      "CREATE TABLE `events` (`name` varchar(64));",
+			want:     []string{"// Events has no primary key, so records cannot be identified.\nfunc (data *Events) ID() uint64 {\n\treturn 0\n}"},
+			unwanted: []string{"PrimaryKey()"},
+		},
+	}
+	for _, tt := range tests {
+		rendered := render(tt.ddl)
+		for _, want := range tt.want {
+			if !strings.Contains(rendered, want) {
+				t.Errorf("%s: rendered model does not contain %q:\n%s", tt.ddl, want, rendered)
+			}
+		}
+		for _, unwanted := range tt.unwanted {
+			if strings.Contains(rendered, unwanted) {
+				t.Errorf("%s: rendered model contains %q:\n%s", tt.ddl, unwanted, rendered)
+			}
+		}
+	}
+}
+
+func TestUpdateModelFileRemovesStaleKeyDeclarations(t *testing.T) {
+	modelTemplate, err := templates.TemplateFS.ReadFile("default/internal/common/models/model.go.templ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	modelData := func(ddl string) template.ModelData {
+		model, err := buildModel(ddl, StructOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return modelTemplateData(model, "example.com/project", ddl)
+	}
+	path := filepath.Join(t.TempDir(), "model.go")
+	composite := "CREATE TABLE `memberships` (`team_id` bigint NOT NULL, `user_id` bigint NOT NULL, PRIMARY KEY (`team_id`, `user_id`));"
+	if err := template.CreateFile(string(modelTemplate), modelData(composite), path); err != nil {
+		t.Fatal(err)
+	}
+
+	single := "CREATE TABLE `memberships` (`id` bigint unsigned NOT NULL, `team_id` bigint NOT NULL, `user_id` bigint NOT NULL, PRIMARY KEY (`id`));"
+	if _, err := updateModelFile(path, modelData(single), string(modelTemplate)); err != nil {
+		t.Fatalf("updateModelFile() error = %v", err)
+	}
+	updated, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(updated)
+	if strings.Contains(text, "MembershipsKey") || !strings.Contains(text, "return data.Id") {
+		t.Fatalf("stale composite key declarations remain:\n%s", text)
+	}
+
+	if _, err := updateModelFile(path, modelData(composite), string(modelTemplate)); err != nil {
+		t.Fatalf("updateModelFile() back to composite error = %v", err)
+	}
+	updated, _ = os.ReadFile(path)
+	if !strings.Contains(string(updated), "type MembershipsKey struct") || !strings.Contains(string(updated), "func (data *Memberships) Key() MembershipsKey") {
+		t.Fatalf("composite key declarations were not added:\n%s", updated)
+	}
+}
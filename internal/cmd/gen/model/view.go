@@ -0,0 +1,416 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const viewHeaderPattern = "(?:ALGORITHM\\s*=\\s*\\w+\\s+)?(?:DEFINER\\s*=\\s*\\S+\\s+)?(?:SQL\\s+SECURITY\\s+\\w+\\s+)?VIEW\\s+"
+
+var (
+	createViewRE = regexp.MustCompile("(?is)^(?:CREATE\\s+(OR\\s+REPLACE\\s+)?|(ALTER)\\s+)" + viewHeaderPattern + "(IF\\s+NOT\\s+EXISTS\\s+)?" +
+		tableNamePattern + "\\s*(?:\\(([^)]*)\\)\\s*)?AS\\s+(.+?)(?:\\s+WITH\\s+(?:CASCADED\\s+|LOCAL\\s+)?CHECK\\s+OPTION)?$")
+	dropViewRE = regexp.MustCompile("(?is)^DROP\\s+VIEW\\s+(IF\\s+EXISTS\\s+)?(.+?)(?:\\s+(?:RESTRICT|CASCADE))?$")
+
+	tableReferenceRE = regexp.MustCompile("(?is)(?:^|,|\\b(?:(LEFT|RIGHT)\\s+(?:OUTER\\s+)?|INNER\\s+|CROSS\\s+|NATURAL\\s+|STRAIGHT_)?JOIN\\b)\\s*" +
+		tableNamePattern + "(?:\\s+(?:AS\\s+)?(" + identifierPattern + "))?")
+	columnReferenceRE = regexp.MustCompile("(?s)^(?:(" + identifierPattern + ")\\s*\\.\\s*)?(" + identifierPattern + ")$")
+	selectAliasRE     = regexp.MustCompile("(?is)^(.*?\\S)\\s+(?:(AS)\\s+)?(" + identifierPattern + ")$")
+	castRE            = regexp.MustCompile("(?is)^CAST\\s*\\((.+)\\s+AS\\s+([a-z]+(?:\\s+[a-z]+)?)\\s*(\\([^)]*\\))?\\s*\\)$")
+	functionCallRE    = regexp.MustCompile("(?is)^([a-z_]+)\\s*\\((.*)\\)$")
+	integerLiteralRE  = regexp.MustCompile(`^-?\d+$`)
+	decimalLiteralRE  = regexp.MustCompile(`^-?\d*\.\d+$`)
+	// operatorSuffixRE matches a select expression that ends in an operator,
+	// so that the word after it is an operand rather than an alias.
+	operatorSuffixRE = regexp.MustCompile(`(?i)(?:[-+*/%=<>&|^~!,(]|\b(?:DIV|MOD|AND|OR|XOR|NOT|IS|LIKE|IN|REGEXP|BETWEEN|CASE|WHEN|THEN|ELSE|INTERVAL|BINARY|DISTINCT))$`)
+)
+
+// reservedAliasWords are keywords that end select expressions and so are not
+// aliases written without AS.
+var reservedAliasWords = map[string]bool{
+	"END": true, "NULL": true, "TRUE": true, "FALSE": true, "AND": true, "OR": true, "NOT": true,
+	"ON": true, "USING": true, "JOIN": true, "LEFT": true, "RIGHT": true, "INNER": true, "CROSS": true,
+	"NATURAL": true, "WHERE": true, "STRAIGHT_JOIN": true, "OUTER": true,
+}
+
+// columnAttributeWords start the column attributes that follow the type in a
+// column definition.
+var columnAttributeWords = []string{
+	"NOT", "NULL", "DEFAULT", "AUTO_INCREMENT", "PRIMARY", "UNIQUE", "KEY", "COMMENT", "ON", "GENERATED",
+	"AS", "CHECK", "REFERENCES", "VISIBLE", "INVISIBLE", "SRID", "STORAGE", "COLUMN_FORMAT", "CONSTRAINT",
+}
+
+// createView applies CREATE [OR REPLACE] VIEW and ALTER VIEW. The view's
+// columns are resolved from its select list against the tables replayed so
+// far.
+func (r *schemaReplay) createView(matches []string) error {
+	orReplace, alter, ifNotExists := matches[1] != "", matches[2] != "", matches[3] != ""
+	name, columnList, query := unquoteIdentifier(matches[4]), matches[5], matches[6]
+
+	i := r.find(name)
+	switch {
+	case i >= 0 && !r.tables[i].view:
+		return fmt.Errorf("%s is a table", name)
+	case i >= 0 && ifNotExists:
+		return nil
+	case i >= 0 && !orReplace && !alter:
+		return fmt.Errorf("view %s already exists", name)
+	case i < 0 && alter:
+		return fmt.Errorf("view %s does not exist", name)
+	}
+
+	columns, err := r.viewColumns(name, query)
+	if err != nil {
+		return err
+	}
+	if columnList != "" {
+		names := splitIdentifierList(columnList)
+		if len(names) != len(columns) {
+			return fmt.Errorf("view %s lists %d columns for %d selected", name, len(names), len(columns))
+		}
+		for j := range columns {
+			columns[j].Name = names[j]
+		}
+	}
+	view := &replayedTable{schema: &TableSchema{Name: name, Columns: columns}, view: true}
+	if i >= 0 {
+		r.tables[i] = view
+	} else {
+		r.tables = append(r.tables, view)
+	}
+	return nil
+}
+
+func (r *schemaReplay) dropViews(matches []string) error {
+	for _, name := range splitFieldDefinitions(matches[2]) {
+		name, err := parseTableName(name)
+		if err != nil {
+			return err
+		}
+		i := r.find(name)
+		if i < 0 || !r.tables[i].view {
+			if i < 0 && matches[1] != "" {
+				continue
+			}
+			return fmt.Errorf("view %s does not exist", name)
+		}
+		r.tables = slices.Delete(r.tables, i, i+1)
+	}
+	return nil
+}
+
+// viewTable is a table referenced by the FROM clause of a view's query.
+type viewTable struct {
+	alias    string
+	schema   *TableSchema
+	nullable bool
+}
+
+// viewColumns resolves the columns selected by the first SELECT of query.
+// Column references take the type of the referenced column, becoming
+// nullable when outer joined; COUNT, SUM, AVG, MIN, MAX, CAST and literals
+// are typed as MySQL types them. Other expressions need a CAST.
+func (r *schemaReplay) viewColumns(view, query string) ([]ColumnSchema, error) {
+	query = strings.TrimSpace(query)
+	for strings.HasPrefix(query, "(") && matchingParen(query, 0) == len(query)-1 {
+		query = strings.TrimSpace(query[1 : len(query)-1])
+	}
+	if !hasKeywordAt(query, 0, "SELECT") {
+		return nil, fmt.Errorf("view %s: unsupported query: %s", view, statementSummary(query))
+	}
+	body := query[len("SELECT"):]
+	end := len(body)
+	from := findTopLevelKeyword(body, "FROM")
+	if from >= 0 {
+		end = from
+	}
+	for _, keyword := range []string{"UNION", "INTO"} {
+		if i := findTopLevelKeyword(body[:end], keyword); i >= 0 {
+			end = i
+		}
+	}
+	list := strings.TrimSpace(body[:end])
+	for _, modifier := range []string{"DISTINCTROW", "DISTINCT", "ALL", "SQL_CALC_FOUND_ROWS"} {
+		if hasKeywordAt(list, 0, modifier) {
+			list = strings.TrimSpace(list[len(modifier):])
+		}
+	}
+
+	var tables []viewTable
+	if from >= 0 {
+		tables = r.viewTables(body[from+len("FROM"):])
+	}
+	var columns []ColumnSchema
+	for _, item := range splitFieldDefinitions(list) {
+		selected, err := resolveSelectItem(tables, item)
+		if err != nil {
+			return nil, fmt.Errorf("view %s: %w", view, err)
+		}
+		columns = append(columns, selected...)
+	}
+	return columns, nil
+}
+
+// viewTables returns the tables of a FROM clause that exist in the replayed
+// schema. Tables on the optional side of an outer join are nullable.
+func (r *schemaReplay) viewTables(clause string) []viewTable {
+	end := len(clause)
+	for _, keyword := range []string{"WHERE", "GROUP", "HAVING", "ORDER", "LIMIT", "UNION", "WINDOW", "FOR", "INTO"} {
+		if i := findTopLevelKeyword(clause, keyword); i >= 0 && i < end {
+			end = i
+		}
+	}
+	clause = strings.TrimSpace(clause[:end])
+
+	var tables []viewTable
+	// A table without an alias takes the LEFT or RIGHT of the join after it as
+	// its alias, so the join kind is carried over to the next match.
+	var joinKind string
+	for _, matches := range tableReferenceRE.FindAllStringSubmatch(clause, -1) {
+		kind := strings.ToUpper(matches[1])
+		if kind == "" {
+			kind = joinKind
+		}
+		alias := unquoteIdentifier(matches[3])
+		joinKind = ""
+		if reservedAliasWords[strings.ToUpper(alias)] {
+			joinKind, alias = strings.ToUpper(alias), ""
+		}
+		i := r.find(unquoteIdentifier(matches[2]))
+		if i < 0 {
+			continue
+		}
+		table := viewTable{alias: alias, schema: r.tables[i].schema}
+		switch kind {
+		case "LEFT":
+			table.nullable = true
+		case "RIGHT":
+			for j := range tables {
+				tables[j].nullable = true
+			}
+		}
+		tables = append(tables, table)
+	}
+	return tables
+}
+
+func (t viewTable) matches(name string) bool {
+	if t.alias != "" {
+		return strings.EqualFold(t.alias, name)
+	}
+	return strings.EqualFold(t.schema.Name, name)
+}
+
+// resolveSelectItem returns the columns an item of a select list adds to a
+// view: those of the tables for * and <table>.*, and one column otherwise.
+func resolveSelectItem(tables []viewTable, item string) ([]ColumnSchema, error) {
+	item = strings.TrimSpace(item)
+	if item == "*" || strings.HasSuffix(item, ".*") {
+		qualifier := unquoteIdentifier(strings.TrimSpace(strings.TrimSuffix(item, ".*")))
+		var columns []ColumnSchema
+		for _, table := range tables {
+			if item != "*" && !table.matches(qualifier) {
+				continue
+			}
+			for _, column := range table.schema.Columns {
+				columns = append(columns, ColumnSchema{Name: column.Name, Definition: viewColumnDefinition(column.Definition, table.nullable)})
+			}
+		}
+		if len(columns) == 0 {
+			return nil, fmt.Errorf("no table for %s", item)
+		}
+		return columns, nil
+	}
+
+	expression, alias := item, ""
+	if matches := selectAliasRE.FindStringSubmatch(item); matches != nil && isSelectAlias(matches) {
+		expression, alias = strings.TrimSpace(matches[1]), unquoteIdentifier(matches[3])
+	}
+	definition, name, err := resolveExpression(tables, expression)
+	if err != nil {
+		return nil, err
+	}
+	if alias != "" {
+		name = alias
+	}
+	if name == "" {
+		return nil, fmt.Errorf("give the expression %s an alias", expression)
+	}
+	return []ColumnSchema{{Name: name, Definition: definition}}, nil
+}
+
+// isSelectAlias reports whether the last word of a select item matched by
+// selectAliasRE is its alias. Without AS, a keyword, a number or a word
+// following an operator is part of the expression instead.
+func isSelectAlias(matches []string) bool {
+	expression, as, word := matches[1], matches[2], matches[3]
+	if strings.HasSuffix(expression, ".") {
+		return false
+	}
+	if as != "" {
+		return true
+	}
+	return !reservedAliasWords[strings.ToUpper(word)] && !integerLiteralRE.MatchString(word) && !operatorSuffixRE.MatchString(expression)
+}
+
+// resolveExpression returns the column definition of a select expression and
+// the column name MySQL gives it without an alias, if it is a column.
+func resolveExpression(tables []viewTable, expression string) (string, string, error) {
+	if matches := columnReferenceRE.FindStringSubmatch(expression); matches != nil && !integerLiteralRE.MatchString(expression) {
+		qualifier, name := unquoteIdentifier(matches[1]), unquoteIdentifier(matches[2])
+		for _, table := range tables {
+			if qualifier != "" && !table.matches(qualifier) {
+				continue
+			}
+			if column, ok := table.schema.Column(name); ok {
+				return viewColumnDefinition(column.Definition, table.nullable), column.Name, nil
+			}
+		}
+		return "", "", fmt.Errorf("unknown column %s", expression)
+	}
+
+	switch {
+	case integerLiteralRE.MatchString(expression):
+		return "bigint NOT NULL", "", nil
+	case decimalLiteralRE.MatchString(expression):
+		return "decimal(65,30) NOT NULL", "", nil
+	case strings.HasPrefix(expression, "'") && strings.HasSuffix(expression, "'"):
+		return "varchar(255) NOT NULL", "", nil
+	}
+	if matches := castRE.FindStringSubmatch(expression); matches != nil {
+		if definition, ok := castType(matches[2], matches[3]); ok {
+			return definition, "", nil
+		}
+	}
+	if matches := functionCallRE.FindStringSubmatch(expression); matches != nil {
+		switch strings.ToUpper(matches[1]) {
+		case "COUNT":
+			return "bigint NOT NULL", "", nil
+		case "SUM", "AVG":
+			return "decimal(65,30)", "", nil
+		case "MIN", "MAX", "ANY_VALUE", "IFNULL", "COALESCE":
+			args := splitFieldDefinitions(matches[2])
+			if len(args) > 0 {
+				if definition, _, err := resolveExpression(tables, args[0]); err == nil {
+					return viewColumnDefinition(definition, true), "", nil
+				}
+			}
+		}
+	}
+	return "", "", fmt.Errorf("cannot infer the type of %s; wrap it in CAST(... AS <type>)", expression)
+}
+
+// castType returns the column definition of CAST(... AS <target><args>).
+func castType(target, args string) (string, bool) {
+	target = strings.ToUpper(strings.Join(strings.Fields(target), " "))
+	switch target {
+	case "SIGNED", "SIGNED INTEGER":
+		return "bigint", true
+	case "UNSIGNED", "UNSIGNED INTEGER":
+		return "bigint unsigned", true
+	case "CHAR", "NCHAR":
+		if args == "" {
+			return "text", true
+		}
+		return "varchar" + args, true
+	case "BINARY":
+		if args == "" {
+			return "blob", true
+		}
+		return "varbinary" + args, true
+	case "DECIMAL", "DOUBLE", "FLOAT", "REAL", "DATE", "DATETIME", "TIME", "YEAR", "JSON":
+		return strings.ToLower(target) + args, true
+	}
+	return "", false
+}
+
+// viewColumnDefinition reduces a column definition to what a view column
+// keeps: its type, NOT NULL unless nullable, and its comment.
+func viewColumnDefinition(definition string, nullable bool) string {
+	end := len(definition)
+	for _, word := range columnAttributeWords {
+		if i := findTopLevelKeyword(definition, word); i >= 0 && i < end {
+			end = i
+		}
+	}
+	upper := strings.ToUpper(definition)
+	notNull := !nullable && (findTopLevelKeyword(upper, "NOT NULL") >= 0 || findTopLevelKeyword(upper, "PRIMARY KEY") >= 0)
+	return viewColumnType(strings.TrimSpace(definition[:end]), notNull, extractComment(definition))
+}
+
+// viewColumnType builds the definition of a view column from its type,
+// nullability and comment.
+func viewColumnType(columnType string, notNull bool, comment string) string {
+	parts := []string{columnType}
+	if notNull {
+		parts = append(parts, "NOT NULL")
+	}
+	if comment != "" {
+		parts = append(parts, "COMMENT "+quoteSQLString(comment))
+	}
+	return strings.Join(parts, " ")
+}
+
+// findTopLevelKeyword returns the index of the first whole-word occurrence of
+// keyword in s outside quotes and parentheses, or -1. Spaces in keyword match
+// any whitespace.
+func findTopLevelKeyword(s, keyword string) int {
+	words := strings.Fields(keyword)
+	level := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if quote != 0 {
+			if ch == '\\' && quote != '`' {
+				i++
+			} else if ch == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch ch {
+		case '\'', '"', '`':
+			quote = ch
+			continue
+		case '(':
+			level++
+			continue
+		case ')':
+			level--
+			continue
+		}
+		if level == 0 && hasKeywordsAt(s, i, words) {
+			return i
+		}
+	}
+	return -1
+}
+
+// hasKeywordsAt reports whether the words start at s[i], separated by
+// whitespace.
+func hasKeywordsAt(s string, i int, words []string) bool {
+	for j, word := range words {
+		if j > 0 {
+			start := i
+			for i < len(s) && (s[i] == ' ' || s[i] == '\t' || s[i] == '\n' || s[i] == '\r') {
+				i++
+			}
+			if i == start {
+				return false
+			}
+		}
+		if !hasKeywordAt(s, i, word) {
+			return false
+		}
+		i += len(word)
+	}
+	return true
+}
+
+// quoteSQLString quotes s as a MySQL string literal.
+func quoteSQLString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSchemaReplayResolvesViewColumns(t *testing.T) {
+	var replay schemaReplay
+	for _, statement := range []string{
+		"CREATE TABLE users (`id` bigint NOT NULL AUTO_INCREMENT, `name` varchar(64) NOT NULL DEFAULT '' COMMENT 'display name', PRIMARY KEY (`id`))",
+		"CREATE TABLE orders (id bigint NOT NULL, user_id bigint NOT NULL, amount decimal(10,2) NOT NULL)",
+		"CREATE VIEW user_totals AS SELECT u.id, u.name AS user_name, COUNT(o.id) orders, SUM(o.amount) AS total, " +
+			"MAX(o.amount) AS largest, CAST(u.id AS CHAR(20)) AS code FROM users u LEFT JOIN orders AS o ON o.user_id = u.id GROUP BY u.id",
+		"CREATE OR REPLACE VIEW named (order_id, buyer) AS SELECT orders.id, users.name FROM orders RIGHT JOIN users ON users.id = orders.user_id",
+		"CREATE VIEW everything AS SELECT * FROM users WHERE id > 0",
+		"DROP VIEW everything",
+	} {
+		if err := replay.apply(statement); err != nil {
+			t.Fatalf("apply(%q) error = %v", statement, err)
+		}
+	}
+
+	var got []string
+	for _, definition := range replay.definitions() {
+		if definition.View {
+			got = append(got, definition.CreateTable)
+		}
+	}
+	want := []string{
+		"CREATE TABLE `user_totals` (\n  `id` bigint NOT NULL,\n  `user_name` varchar(64) NOT NULL COMMENT 'display name',\n  `orders` bigint NOT NULL,\n" +
+			"  `total` decimal(65,30),\n  `largest` decimal(10,2),\n  `code` varchar(20)\n);",
+		"CREATE TABLE `named` (\n  `order_id` bigint,\n  `buyer` varchar(64) NOT NULL COMMENT 'display name'\n);",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("views = %q", got)
+	}
+	if tables := tableStatements(replay.definitions()); len(tables) != 2 {
+		t.Fatalf("tables = %q", tables)
+	}
+}
+
+func TestSchemaReplayReportsInvalidViews(t *testing.T) {
+	tests := []struct {
+		statements []string
+		want       string
+	}{
+		{[]string{"CREATE TABLE t (a int)", "CREATE VIEW v AS SELECT a + 1 AS b FROM t"}, "cannot infer the type of a + 1"},
+		{[]string{"CREATE TABLE t (a int)", "CREATE VIEW v AS SELECT a + 1 FROM t"}, "cannot infer the type of a + 1"},
+		{[]string{"CREATE TABLE t (a int, b int)", "CREATE VIEW v AS SELECT a * b FROM t"}, "cannot infer the type of a * b"},
+		{[]string{"CREATE TABLE t (a int, b int)", "CREATE VIEW v AS SELECT a DIV b FROM t"}, "cannot infer the type of a DIV b"},
+		{[]string{"CREATE TABLE t (a int)", "CREATE VIEW v AS SELECT missing FROM t"}, "unknown column missing"},
+		{[]string{"CREATE TABLE t (a int)", "CREATE VIEW v (x, y) AS SELECT a FROM t"}, "lists 2 columns for 1 selected"},
+		{[]string{"CREATE TABLE t (a int)", "CREATE VIEW v AS SELECT a FROM t", "CREATE VIEW v AS SELECT a FROM t"}, "view v already exists"},
+		{[]string{"CREATE TABLE t (a int)", "CREATE VIEW t AS SELECT a FROM t"}, "t is a table"},
+		{[]string{"CREATE TABLE t (a int)", "CREATE VIEW v AS SELECT a FROM t", "ALTER TABLE v ADD b int"}, "v is a view"},
+		{[]string{"CREATE TABLE t (a int)", "DROP VIEW t"}, "view t does not exist"},
+	}
+	for _, tt := range tests {
+		var replay schemaReplay
+		var err error
+		for _, statement := range tt.statements {
+			if err = replay.apply(statement); err != nil {
+				break
+			}
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("apply(%q) error = %v, want %q", tt.statements, err, tt.want)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package init
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -24,6 +25,34 @@ func TestGenerateProjectCreatesScaffold(t *testing.T) {
 	}
 }
 
+// TestGenerateProjectCommonPackagesBuild compiles the shared packages of a new
+// project, such as the database and model base types, against the dependency
+// versions of its go.mod. It is skipped when the dependencies cannot be
+// downloaded.
+func TestGenerateProjectCommonPackagesBuild(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a generated project")
+	}
+	changeWorkingDirectory(t, t.TempDir())
+	if err := generateProject("example.com/myapp"); err != nil {
+		t.Fatalf("generateProject() error = %v", err)
+	}
+	project := filepath.Join("example.com", "myapp")
+
+	goCommand := func(args ...string) ([]byte, error) {
+		cmd := exec.Command("go", args...)
+		cmd.Dir = project
+		cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off")
+		return cmd.CombinedOutput()
+	}
+	if output, err := goCommand("mod", "download"); err != nil {
+		t.Skipf("download project dependencies: %v\n%s", err, output)
+	}
+	if output, err := goCommand("build", "./internal/common/..."); err != nil {
+		t.Fatalf("build generated common packages: %v\n%s", err, output)
+	}
+}
+
 func TestGenerateProjectRejectsExistingTarget(t *testing.T) {
 	changeWorkingDirectory(t, t.TempDir())
 
@@ -0,0 +1,118 @@
+package migrate
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Manage versioned database migrations",
+	Long:  "Create timestamped migration files under migrations/ and apply them to the database described by a config file.\nApplied versions are tracked with checksums in the schema_migrations table, which also holds a lock row while a run is in progress.",
+}
+
+var newCmd = &cobra.Command{
+	Use:     "new <name>",
+	Short:   "Create a pair of up/down migration files",
+	Example: "  godo migrate new create_users\n  godo migrate new add-email-to-users",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, _ := cmd.Flags().GetString("dir")
+		return newMigration(dir, args[0], cmd.OutOrStdout())
+	},
+}
+
+var upCmd = &cobra.Command{
+	Use:     "up",
+	Short:   "Apply all pending migrations",
+	Example: "  godo migrate up --config config.yaml",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMigrator(cmd, func(m *migrator) error { return m.up() })
+	},
+}
+
+var downCmd = &cobra.Command{
+	Use:     "down",
+	Short:   "Roll back the most recently applied migrations",
+	Example: "  godo migrate down --config config.yaml\n  godo migrate down --steps 3 --config config.yaml",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		steps, _ := cmd.Flags().GetInt("steps")
+		return runMigrator(cmd, func(m *migrator) error { return m.down(steps) })
+	},
+}
+
+var toCmd = &cobra.Command{
+	Use:     "to <version>",
+	Short:   "Migrate up or down to a specific version",
+	Long:    "Apply pending migrations up to and including <version> and roll back applied migrations newer than it. Version 0 rolls back every migration.",
+	Example: "  godo migrate to 20240101120000 --config config.yaml\n  godo migrate to 0 --config config.yaml",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		version, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil || version < 0 {
+			return fmt.Errorf("invalid migration version %q", args[0])
+		}
+		return runMigrator(cmd, func(m *migrator) error { return m.to(version) })
+	},
+}
+
+var statusCmd = &cobra.Command{
+	Use:     "status",
+	Short:   "Show applied and pending migrations",
+	Example: "  godo migrate status --config config.yaml",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMigrator(cmd, func(m *migrator) error {
+			statuses, err := m.status()
+			if err != nil {
+				return err
+			}
+			return printStatus(cmd.OutOrStdout(), statuses)
+		})
+	},
+}
+
+var unlockCmd = &cobra.Command{
+	Use:     "unlock",
+	Short:   "Release a migration lock left by an interrupted run",
+	Example: "  godo migrate unlock --config config.yaml",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMigrator(cmd, func(m *migrator) error { return m.unlock() })
+	},
+}
+
+var diffCmd = &cobra.Command{
+	Use:     "diff <old> <new>",
+	Short:   "Generate a migration from the differences between two schemas",
+	Long:    "Compare two schemas, each given as a SQL file or a database config file, and write the ALTER TABLE statements\nfor added, dropped and modified columns and indexes as a new up/down migration for review.",
+	Example: "  godo migrate diff schema.old.sql schema.sql\n  godo migrate diff config.yaml schema.sql --name add_user_email",
+	Args:    cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, _ := cmd.Flags().GetString("dir")
+		name, _ := cmd.Flags().GetString("name")
+		return diffMigration(dir, name, args[0], args[1], cmd.OutOrStdout())
+	},
+}
+
+func runMigrator(cmd *cobra.Command, fn func(m *migrator) error) error {
+	configPath, _ := cmd.Flags().GetString("config")
+	dir, _ := cmd.Flags().GetString("dir")
+	return withMigrator(configPath, dir, cmd.OutOrStdout(), fn)
+}
+
+func GetCommand() *cobra.Command {
+	return migrateCmd
+}
+
+func init() {
+	migrateCmd.PersistentFlags().StringP("dir", "", "migrations", "Directory holding migration files, relative to the project root")
+	migrateCmd.PersistentFlags().StringP("config", "c", "", "Database config file (the same format used by 'godo gen model')")
+	downCmd.Flags().IntP("steps", "n", 1, "Number of migrations to roll back")
+	diffCmd.Flags().StringP("name", "", "schema_diff", "Name of the generated migration")
+	migrateCmd.AddCommand(newCmd, upCmd, downCmd, toCmd, statusCmd, unlockCmd, diffCmd)
+}
@@ -0,0 +1,166 @@
+package migrate
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	genmodel "github.com/jiajia556/godo/internal/cmd/gen/model"
+)
+
+// diffMigration compares two schemas, each read from a SQL file or a database
+// config file, and writes the ALTER statements as a new migration.
+func diffMigration(dir, name, oldFrom, newFrom string, out io.Writer) error {
+	oldTables, err := genmodel.LoadTableSchemas(oldFrom)
+	if err != nil {
+		return fmt.Errorf("load old schema: %w", err)
+	}
+	newTables, err := genmodel.LoadTableSchemas(newFrom)
+	if err != nil {
+		return fmt.Errorf("load new schema: %w", err)
+	}
+
+	up := diffSchemas(oldTables, newTables)
+	if len(up) == 0 {
+		_, err := fmt.Fprintln(out, "no schema differences found")
+		return err
+	}
+	down := diffSchemas(newTables, oldTables)
+
+	dir, err = resolveMigrationsDir(dir)
+	if err != nil {
+		return err
+	}
+	upPath, downPath, err := createMigrationFiles(dir, name, time.Now(),
+		strings.Join(up, "\n\n")+"\n", strings.Join(down, "\n\n")+"\n")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "created %s\ncreated %s\n", upPath, downPath)
+	return err
+}
+
+// diffSchemas returns the statements that turn the from schema into the to
+// schema. Renamed tables, columns and indexes appear as a drop and an add.
+func diffSchemas(from, to []*genmodel.TableSchema) []string {
+	fromByName := tablesByName(from)
+	toByName := tablesByName(to)
+
+	var statements []string
+	for _, table := range to {
+		previous, ok := fromByName[strings.ToLower(table.Name)]
+		if !ok {
+			statements = append(statements, table.CreateStatement())
+			continue
+		}
+		statements = append(statements, diffTable(previous, table)...)
+	}
+	for _, table := range from {
+		if _, ok := toByName[strings.ToLower(table.Name)]; !ok {
+			statements = append(statements, "DROP TABLE "+genmodel.QuoteIdentifier(table.Name)+";")
+		}
+	}
+	return statements
+}
+
+// diffTable returns the ALTER TABLE statements turning from into to. Changed
+// indexes are dropped before columns change and re-added afterwards.
+func diffTable(from, to *genmodel.TableSchema) []string {
+	alter := "ALTER TABLE " + genmodel.QuoteIdentifier(to.Name) + " "
+	fromIndexes := indexesByName(from.Indexes)
+	toIndexes := indexesByName(to.Indexes)
+
+	var statements []string
+	for _, index := range from.Indexes {
+		next, ok := toIndexes[strings.ToLower(index.Name)]
+		if ok && next.Definition() == index.Definition() {
+			continue
+		}
+		if index.Kind == genmodel.IndexPrimary {
+			statements = append(statements, alter+"DROP PRIMARY KEY;")
+		} else {
+			statements = append(statements, alter+"DROP INDEX "+genmodel.QuoteIdentifier(index.Name)+";")
+		}
+	}
+
+	for position, column := range to.Columns {
+		previous, ok := from.Column(column.Name)
+		switch {
+		case !ok:
+			placement := " FIRST"
+			if position > 0 {
+				placement = " AFTER " + genmodel.QuoteIdentifier(to.Columns[position-1].Name)
+			}
+			statements = append(statements, alter+"ADD COLUMN "+genmodel.QuoteIdentifier(column.Name)+" "+column.Definition+placement+";")
+		case normalizeDefinition(previous.Definition) != normalizeDefinition(column.Definition):
+			statements = append(statements, alter+"MODIFY COLUMN "+genmodel.QuoteIdentifier(column.Name)+" "+column.Definition+";")
+		}
+	}
+	for _, column := range from.Columns {
+		if _, ok := to.Column(column.Name); !ok {
+			statements = append(statements, alter+"DROP COLUMN "+genmodel.QuoteIdentifier(column.Name)+";")
+		}
+	}
+
+	for _, index := range to.Indexes {
+		previous, ok := fromIndexes[strings.ToLower(index.Name)]
+		if ok && previous.Definition() == index.Definition() {
+			continue
+		}
+		statements = append(statements, alter+"ADD "+index.Definition()+";")
+	}
+	return statements
+}
+
+func tablesByName(tables []*genmodel.TableSchema) map[string]*genmodel.TableSchema {
+	byName := make(map[string]*genmodel.TableSchema, len(tables))
+	for _, table := range tables {
+		byName[strings.ToLower(table.Name)] = table
+	}
+	return byName
+}
+
+func indexesByName(indexes []genmodel.IndexSchema) map[string]genmodel.IndexSchema {
+	byName := make(map[string]genmodel.IndexSchema, len(indexes))
+	for _, index := range indexes {
+		byName[strings.ToLower(index.Name)] = index
+	}
+	return byName
+}
+
+// normalizeDefinition collapses whitespace and upper-cases everything outside
+// quotes so that formatting differences are not reported as changes.
+func normalizeDefinition(definition string) string {
+	var sb strings.Builder
+	var quote byte
+	space := false
+	for i := 0; i < len(definition); i++ {
+		ch := definition[i]
+		if quote != 0 {
+			sb.WriteByte(ch)
+			if ch == '\\' && quote != '`' && i+1 < len(definition) {
+				i++
+				sb.WriteByte(definition[i])
+			} else if ch == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch {
+		case ch == '\'' || ch == '"' || ch == '`':
+			quote = ch
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			space = true
+			continue
+		case ch >= 'a' && ch <= 'z':
+			ch -= 'a' - 'A'
+		}
+		if space && sb.Len() > 0 {
+			sb.WriteByte(' ')
+		}
+		space = false
+		sb.WriteByte(ch)
+	}
+	return sb.String()
+}
@@ -0,0 +1,102 @@
+package migrate
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	genmodel "github.com/jiajia556/godo/internal/cmd/gen/model"
+)
+
+func mustParseTable(t *testing.T, ddl string) *genmodel.TableSchema {
+	t.Helper()
+	table, err := genmodel.ParseCreateTable(ddl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return table
+}
+
+func TestDiffSchemasAltersColumnsAndIndexes(t *testing.T) {
+	before := []*genmodel.TableSchema{
+		mustParseTable(t, "CREATE TABLE users (id bigint NOT NULL, name varchar(64), legacy int, PRIMARY KEY (id), KEY idx_name (name));"),
+		mustParseTable(t, "CREATE TABLE old_logs (id bigint);"),
+	}
+	after := []*genmodel.TableSchema{
+		mustParseTable(t, "CREATE TABLE `users` (`id` BIGINT   not null, `email` varchar(128) NOT NULL, `name` varchar(128), PRIMARY KEY (`id`), KEY `idx_name` (`name`,`email`), UNIQUE KEY `uk_email` (`email`));"),
+		mustParseTable(t, "CREATE TABLE posts (id bigint, PRIMARY KEY (id));"),
+	}
+
+	up := diffSchemas(before, after)
+	want := []string{
+		"ALTER TABLE `users` DROP INDEX `idx_name`;",
+		"ALTER TABLE `users` ADD COLUMN `email` varchar(128) NOT NULL AFTER `id`;",
+		"ALTER TABLE `users` MODIFY COLUMN `name` varchar(128);",
+		"ALTER TABLE `users` DROP COLUMN `legacy`;",
+		"ALTER TABLE `users` ADD KEY `idx_name` (`name`,`email`);",
+		"ALTER TABLE `users` ADD UNIQUE KEY `uk_email` (`email`);",
+		"CREATE TABLE `posts` (\n  `id` bigint,\n  PRIMARY KEY (`id`)\n);",
+		"DROP TABLE `old_logs`;",
+	}
+	if !reflect.DeepEqual(up, want) {
+		t.Fatalf("up statements:\n%s", strings.Join(up, "\n"))
+	}
+
+	down := diffSchemas(after, before)
+	for _, expected := range []string{
+		"ALTER TABLE `users` DROP INDEX `uk_email`;",
+		"ALTER TABLE `users` ADD COLUMN `legacy` int AFTER `name`;",
+		"ALTER TABLE `users` DROP COLUMN `email`;",
+		"DROP TABLE `posts`;",
+	} {
+		if !containsString(down, expected) {
+			t.Errorf("down statements do not contain %q:\n%s", expected, strings.Join(down, "\n"))
+		}
+	}
+	if len(diffSchemas(after, after)) != 0 {
+		t.Fatal("identical schemas produced statements")
+	}
+}
+
+func TestDiffMigrationWritesReviewableFiles(t *testing.T) {
+	root := t.TempDir()
+	oldSchema := filepath.Join(root, "old.sql")
+	newSchema := filepath.Join(root, "new.sql")
+	if err := os.WriteFile(oldSchema, []byte("CREATE TABLE users (id bigint);"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(newSchema, []byte("CREATE TABLE users (id bigint, name varchar(64));"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(root, "migrations")
+	var out bytes.Buffer
+	if err := diffMigration(dir, "add_name", oldSchema, newSchema, &out); err != nil {
+		t.Fatalf("diffMigration() error = %v", err)
+	}
+	migrations, err := loadMigrations(dir)
+	if err != nil || len(migrations) != 1 || migrations[0].name != "add_name" {
+		t.Fatalf("migrations = %+v, %v", migrations, err)
+	}
+	up, _ := os.ReadFile(migrations[0].upPath)
+	down, _ := os.ReadFile(migrations[0].downPath)
+	if !strings.Contains(string(up), "ADD COLUMN `name` varchar(64) AFTER `id`;") || !strings.Contains(string(down), "DROP COLUMN `name`;") {
+		t.Fatalf("up = %s\ndown = %s", up, down)
+	}
+
+	out.Reset()
+	if err := diffMigration(dir, "noop", newSchema, newSchema, &out); err != nil || !strings.Contains(out.String(), "no schema differences") {
+		t.Fatalf("diffMigration(identical) = %v, %s", err, out.String())
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
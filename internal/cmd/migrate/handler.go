@@ -0,0 +1,77 @@
+package migrate
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jiajia556/godo/internal/service"
+)
+
+func resolveMigrationsDir(dir string) (string, error) {
+	if strings.TrimSpace(dir) == "" {
+		return "", fmt.Errorf("migrations directory is empty")
+	}
+	path, err := service.GetAbsPath(dir)
+	if err != nil {
+		return "", fmt.Errorf("resolve migrations directory: %w", err)
+	}
+	return path, nil
+}
+
+func newMigration(dir, name string, out io.Writer) error {
+	dir, err := resolveMigrationsDir(dir)
+	if err != nil {
+		return err
+	}
+	upPath, downPath, err := createMigrationFiles(dir, name, time.Now(), "", "")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "created %s\ncreated %s\n", upPath, downPath)
+	return err
+}
+
+// withMigrator opens the database described by the config file and runs fn
+// with a migrator for the migrations directory.
+func withMigrator(configPath, dir string, out io.Writer, fn func(m *migrator) error) (err error) {
+	if strings.TrimSpace(configPath) == "" {
+		return fmt.Errorf("database config file is required; pass --config")
+	}
+	dir, err = resolveMigrationsDir(dir)
+	if err != nil {
+		return err
+	}
+	if err := service.LoadConfig(configPath); err != nil {
+		return err
+	}
+	db, err := service.OpenDatabase(service.GetConfig())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := db.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("close database: %w", closeErr)
+		}
+	}()
+	return fn(newMigrator(db, dir, out))
+}
+
+func printStatus(out io.Writer, statuses []migrationStatus) error {
+	if len(statuses) == 0 {
+		_, err := fmt.Fprintln(out, "no migrations found")
+		return err
+	}
+	writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "VERSION\tNAME\tSTATE\tAPPLIED AT")
+	for _, status := range statuses {
+		appliedAt := status.appliedAt
+		if appliedAt == "" {
+			appliedAt = "-"
+		}
+		fmt.Fprintf(writer, "%d\t%s\t%s\t%s\n", status.version, status.name, status.state, appliedAt)
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,160 @@
+package migrate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	versionLayout = "20060102150405"
+	upSuffix      = ".up.sql"
+	downSuffix    = ".down.sql"
+)
+
+var (
+	migrationFileRE = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+	migrationNameRE = regexp.MustCompile(`^[a-z0-9_]+$`)
+	// migrationLikeRE matches files meant as migrations, whose names are then
+	// checked against migrationFileRE instead of being skipped.
+	migrationLikeRE = regexp.MustCompile(`(?i)^\d+_.*\.(up|down)\.sql$`)
+)
+
+// migration is a pair of up/down SQL files sharing a version.
+type migration struct {
+	version  int64
+	name     string
+	upPath   string
+	downPath string
+}
+
+// checksum returns the SHA-256 of the up script, used to detect files edited
+// after they were applied.
+func (m migration) checksum() (string, error) {
+	content, err := os.ReadFile(m.upPath)
+	if err != nil {
+		return "", fmt.Errorf("read migration %s: %w", m.upPath, err)
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// normalizeMigrationName turns a user-supplied name into the snake_case form
+// used in migration file names.
+func normalizeMigrationName(name string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(name))
+	normalized = strings.NewReplacer("-", "_", " ", "_").Replace(normalized)
+	if !migrationNameRE.MatchString(normalized) {
+		return "", fmt.Errorf("invalid migration name %q; use letters, digits, '-' and '_'", name)
+	}
+	return normalized, nil
+}
+
+// createMigrationFiles writes <version>_<name>.up.sql and .down.sql files with
+// the given SQL into dir and returns their paths.
+func createMigrationFiles(dir, name string, now time.Time, up, down string) (string, string, error) {
+	name, err := normalizeMigrationName(name)
+	if err != nil {
+		return "", "", err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", "", fmt.Errorf("create migrations directory %s: %w", dir, err)
+	}
+	existing, err := loadMigrations(dir)
+	if err != nil {
+		return "", "", err
+	}
+	version, err := strconv.ParseInt(now.UTC().Format(versionLayout), 10, 64)
+	if err != nil {
+		return "", "", fmt.Errorf("build migration version: %w", err)
+	}
+	for _, m := range existing {
+		if m.version >= version {
+			version = m.version + 1
+		}
+	}
+
+	base := filepath.Join(dir, fmt.Sprintf("%d_%s", version, name))
+	upPath, downPath := base+upSuffix, base+downSuffix
+	for path, header := range map[string]string{
+		upPath:   "-- " + name + ": apply\n" + up,
+		downPath: "-- " + name + ": revert\n" + down,
+	} {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return "", "", fmt.Errorf("create migration file: %w", err)
+		}
+		_, err = file.WriteString(header)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return "", "", fmt.Errorf("write migration file %s: %w", path, err)
+		}
+	}
+	return upPath, downPath, nil
+}
+
+// loadMigrations reads the migration files in dir sorted by version. A missing
+// directory yields no migrations; a version without an up file, or a migration
+// file whose name is not lowercase snake_case, is an error.
+func loadMigrations(dir string) ([]migration, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read migrations directory %s: %w", dir, err)
+	}
+
+	byVersion := make(map[int64]*migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		matches := migrationFileRE.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			if migrationLikeRE.MatchString(entry.Name()) {
+				return nil, fmt.Errorf("invalid migration file name %s; use <version>_<name>%s with a name of lowercase letters, digits and '_'", entry.Name(), upSuffix)
+			}
+			continue
+		}
+		version, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version in %s", entry.Name())
+		}
+		m := byVersion[version]
+		if m == nil {
+			m = &migration{version: version, name: matches[2]}
+			byVersion[version] = m
+		}
+		if m.name != matches[2] {
+			return nil, fmt.Errorf("migration version %d has conflicting names %q and %q", version, m.name, matches[2])
+		}
+		path := filepath.Join(dir, entry.Name())
+		if matches[3] == "up" {
+			m.upPath = path
+		} else {
+			m.downPath = path
+		}
+	}
+
+	migrations := make([]migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.upPath == "" {
+			return nil, fmt.Errorf("migration %d_%s has no %s file", m.version, m.name, upSuffix)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+	return migrations, nil
+}
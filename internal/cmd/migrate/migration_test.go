@@ -0,0 +1,61 @@
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCreateMigrationFilesUsesTimestampedNames(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "migrations")
+	now := time.Date(2024, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	upPath, downPath, err := createMigrationFiles(dir, "Add-Email to users", now, "", "")
+	if err != nil {
+		t.Fatalf("createMigrationFiles() error = %v", err)
+	}
+	if filepath.Base(upPath) != "20240304050607_add_email_to_users.up.sql" || filepath.Base(downPath) != "20240304050607_add_email_to_users.down.sql" {
+		t.Fatalf("paths = %s, %s", upPath, downPath)
+	}
+	upPath, _, err = createMigrationFiles(dir, "second", now, "", "")
+	if err != nil || filepath.Base(upPath) != "20240304050608_second.up.sql" {
+		t.Fatalf("second migration = %s, %v", upPath, err)
+	}
+
+	migrations, err := loadMigrations(dir)
+	if err != nil || len(migrations) != 2 || migrations[0].name != "add_email_to_users" {
+		t.Fatalf("loadMigrations() = %+v, %v", migrations, err)
+	}
+	if _, _, err := createMigrationFiles(dir, "bad/name", now, "", ""); err == nil || !strings.Contains(err.Error(), "invalid migration name") {
+		t.Fatalf("invalid name error = %v", err)
+	}
+}
+
+func TestLoadMigrationsRejectsIncompletePairs(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "20240101000000_users.down.sql"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadMigrations(dir); err == nil || !strings.Contains(err.Error(), "has no .up.sql file") {
+		t.Fatalf("loadMigrations() error = %v", err)
+	}
+
+	for _, name := range []string{"20240102000000_AddEmail.up.sql", "20240102000000_add-email.down.sql", "20240102000000_add_email.UP.SQL"} {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := loadMigrations(dir); err == nil || !strings.Contains(err.Error(), "invalid migration file name "+name) {
+			t.Errorf("loadMigrations(%s) error = %v", name, err)
+		}
+	}
+	migrations, err := loadMigrations(filepath.Join(dir, "missing"))
+	if err != nil || len(migrations) != 0 {
+		t.Fatalf("loadMigrations(missing) = %v, %v", migrations, err)
+	}
+}
@@ -0,0 +1,366 @@
+package migrate
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	genmodel "github.com/jiajia556/godo/internal/cmd/gen/model"
+)
+
+const (
+	migrationsTable = "schema_migrations"
+	// lockVersion is the reserved version of the lock row. Migration versions
+	// are timestamps and therefore always greater than zero.
+	lockVersion = 0
+	lockName    = "__lock__"
+)
+
+const (
+	stateApplied  = "applied"
+	statePending  = "pending"
+	stateModified = "modified"
+	stateMissing  = "missing file"
+)
+
+// appliedMigration is a row of the schema_migrations table.
+type appliedMigration struct {
+	version   int64
+	name      string
+	checksum  string
+	appliedAt string
+}
+
+// migrationStatus describes one migration as reported by status.
+type migrationStatus struct {
+	version   int64
+	name      string
+	state     string
+	appliedAt string
+}
+
+// migrator applies and rolls back the migrations in dir against db.
+type migrator struct {
+	db  *sql.DB
+	dir string
+	out io.Writer
+	now func() time.Time
+}
+
+func newMigrator(db *sql.DB, dir string, out io.Writer) *migrator {
+	return &migrator{db: db, dir: dir, out: out, now: time.Now}
+}
+
+// up applies every pending migration in version order.
+func (m *migrator) up() error {
+	return m.withLock(func(files []migration, applied map[int64]appliedMigration) error {
+		count := 0
+		for _, file := range files {
+			if _, ok := applied[file.version]; ok {
+				continue
+			}
+			if err := m.apply(file); err != nil {
+				return err
+			}
+			count++
+		}
+		if count == 0 {
+			fmt.Fprintln(m.out, "no pending migrations")
+		}
+		return nil
+	})
+}
+
+// down rolls back the most recently applied steps migrations.
+func (m *migrator) down(steps int) error {
+	if steps <= 0 {
+		return fmt.Errorf("steps must be positive, got %d", steps)
+	}
+	return m.withLock(func(files []migration, applied map[int64]appliedMigration) error {
+		versions := appliedVersionsDesc(applied)
+		if len(versions) == 0 {
+			fmt.Fprintln(m.out, "no applied migrations")
+			return nil
+		}
+		byVersion := migrationsByVersion(files)
+		for _, version := range versions[:min(steps, len(versions))] {
+			if err := m.revert(byVersion, applied[version]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// to migrates the database to target: pending migrations up to and including
+// target are applied and applied migrations newer than target are rolled back.
+// A target of 0 rolls back everything.
+func (m *migrator) to(target int64) error {
+	return m.withLock(func(files []migration, applied map[int64]appliedMigration) error {
+		byVersion := migrationsByVersion(files)
+		if _, ok := byVersion[target]; !ok && target != 0 {
+			if _, ok := applied[target]; !ok {
+				return fmt.Errorf("unknown migration version %d", target)
+			}
+		}
+		for _, version := range appliedVersionsDesc(applied) {
+			if version <= target {
+				break
+			}
+			if err := m.revert(byVersion, applied[version]); err != nil {
+				return err
+			}
+		}
+		for _, file := range files {
+			if file.version > target {
+				break
+			}
+			if _, ok := applied[file.version]; ok {
+				continue
+			}
+			if err := m.apply(file); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// status reports every migration found on disk or in the database.
+func (m *migrator) status() ([]migrationStatus, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, err
+	}
+	files, err := loadMigrations(m.dir)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := m.appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []migrationStatus
+	for _, file := range files {
+		row, ok := applied[file.version]
+		if !ok {
+			statuses = append(statuses, migrationStatus{version: file.version, name: file.name, state: statePending})
+			continue
+		}
+		checksum, err := file.checksum()
+		if err != nil {
+			return nil, err
+		}
+		state := stateApplied
+		if checksum != row.checksum {
+			state = stateModified
+		}
+		statuses = append(statuses, migrationStatus{version: file.version, name: file.name, state: state, appliedAt: row.appliedAt})
+		delete(applied, file.version)
+	}
+	for _, version := range appliedVersionsDesc(applied) {
+		row := applied[version]
+		statuses = append(statuses, migrationStatus{version: version, name: row.name, state: stateMissing, appliedAt: row.appliedAt})
+	}
+	return statuses, nil
+}
+
+// unlock removes a lock row left behind by an interrupted run.
+func (m *migrator) unlock() error {
+	if err := m.ensureTable(); err != nil {
+		return err
+	}
+	if _, err := m.db.Exec("DELETE FROM "+migrationsTable+" WHERE version = ?", lockVersion); err != nil {
+		return fmt.Errorf("remove migration lock: %w", err)
+	}
+	return nil
+}
+
+// withLock loads migrations, verifies the checksums of applied ones and runs fn
+// while holding the lock row.
+func (m *migrator) withLock(fn func(files []migration, applied map[int64]appliedMigration) error) (err error) {
+	if err := m.ensureTable(); err != nil {
+		return err
+	}
+	if err := m.lock(); err != nil {
+		return err
+	}
+	defer func() {
+		if unlockErr := m.unlock(); err == nil {
+			err = unlockErr
+		}
+	}()
+
+	files, err := loadMigrations(m.dir)
+	if err != nil {
+		return err
+	}
+	applied, err := m.appliedMigrations()
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		row, ok := applied[file.version]
+		if !ok {
+			continue
+		}
+		checksum, err := file.checksum()
+		if err != nil {
+			return err
+		}
+		if checksum != row.checksum {
+			return fmt.Errorf("checksum mismatch for migration %d_%s: the file changed after it was applied", file.version, file.name)
+		}
+	}
+	return fn(files, applied)
+}
+
+func (m *migrator) ensureTable() error {
+	_, err := m.db.Exec("CREATE TABLE IF NOT EXISTS " + migrationsTable + " (" +
+		"version BIGINT NOT NULL PRIMARY KEY, " +
+		"name VARCHAR(255) NOT NULL, " +
+		"checksum VARCHAR(64) NOT NULL, " +
+		"applied_at VARCHAR(32) NOT NULL)")
+	if err != nil {
+		return fmt.Errorf("create %s table: %w", migrationsTable, err)
+	}
+	return nil
+}
+
+// lock inserts the lock row. The primary key makes the insert fail while
+// another run holds the lock.
+func (m *migrator) lock() error {
+	holder := fmt.Sprintf("pid %d", os.Getpid())
+	if host, err := os.Hostname(); err == nil {
+		holder = host + " " + holder
+	}
+	_, err := m.db.Exec("INSERT INTO "+migrationsTable+" (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)",
+		lockVersion, lockName, holder, m.timestamp())
+	if err == nil {
+		return nil
+	}
+	var heldBy, since string
+	row := m.db.QueryRow("SELECT checksum, applied_at FROM "+migrationsTable+" WHERE version = ?", lockVersion)
+	if scanErr := row.Scan(&heldBy, &since); scanErr != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	return fmt.Errorf("migrations are locked by %s since %s; run 'godo migrate unlock' if no other migration is running", heldBy, since)
+}
+
+func (m *migrator) appliedMigrations() (map[int64]appliedMigration, error) {
+	rows, err := m.db.Query("SELECT version, name, checksum, applied_at FROM "+migrationsTable+" WHERE version <> ?", lockVersion)
+	if err != nil {
+		return nil, fmt.Errorf("query %s: %w", migrationsTable, err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]appliedMigration)
+	for rows.Next() {
+		var row appliedMigration
+		if err := rows.Scan(&row.version, &row.name, &row.checksum, &row.appliedAt); err != nil {
+			return nil, fmt.Errorf("scan %s: %w", migrationsTable, err)
+		}
+		applied[row.version] = row
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate over %s: %w", migrationsTable, err)
+	}
+	return applied, nil
+}
+
+func (m *migrator) apply(file migration) error {
+	checksum, err := file.checksum()
+	if err != nil {
+		return err
+	}
+	err = m.execute(file.upPath, "INSERT INTO "+migrationsTable+" (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)",
+		file.version, file.name, checksum, m.timestamp())
+	if err != nil {
+		return fmt.Errorf("apply migration %d_%s: %w", file.version, file.name, err)
+	}
+	fmt.Fprintf(m.out, "applied %d_%s\n", file.version, file.name)
+	return nil
+}
+
+func (m *migrator) revert(files map[int64]migration, row appliedMigration) error {
+	file, ok := files[row.version]
+	if !ok || file.downPath == "" {
+		return fmt.Errorf("cannot roll back migration %d_%s: %s file not found", row.version, row.name, downSuffix)
+	}
+	err := m.execute(file.downPath, "DELETE FROM "+migrationsTable+" WHERE version = ?", row.version)
+	if err != nil {
+		return fmt.Errorf("roll back migration %d_%s: %w", row.version, row.name, err)
+	}
+	fmt.Fprintf(m.out, "rolled back %d_%s\n", row.version, row.name)
+	return nil
+}
+
+// execute runs the statements of the SQL file at path followed by the
+// bookkeeping statement in a single transaction. MySQL commits DDL statements
+// such as CREATE and ALTER TABLE implicitly, so there a failing migration keeps
+// the statements before the failing one and is not recorded as applied; the
+// error names the statement to fix the schema by hand from.
+func (m *migrator) execute(path, bookkeeping string, args ...any) (err error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+	statements, err := genmodel.SplitSQLStatements(string(content))
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	tx, err := m.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, ignoreTxDone(tx.Rollback()))
+		}
+	}()
+	for i, statement := range statements {
+		if _, err = tx.Exec(statement); err != nil {
+			return fmt.Errorf("execute statement %d of %d %q: %w", i+1, len(statements), statement, err)
+		}
+	}
+	if _, err = tx.Exec(bookkeeping, args...); err != nil {
+		return fmt.Errorf("update %s: %w", migrationsTable, err)
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (m *migrator) timestamp() string {
+	return m.now().UTC().Format(time.RFC3339)
+}
+
+func ignoreTxDone(err error) error {
+	if errors.Is(err, sql.ErrTxDone) {
+		return nil
+	}
+	return err
+}
+
+func migrationsByVersion(files []migration) map[int64]migration {
+	byVersion := make(map[int64]migration, len(files))
+	for _, file := range files {
+		byVersion[file.version] = file
+	}
+	return byVersion
+}
+
+func appliedVersionsDesc(applied map[int64]appliedMigration) []int64 {
+	versions := make([]int64, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+	return versions
+}
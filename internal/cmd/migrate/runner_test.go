@@ -0,0 +1,153 @@
+package migrate
+
+import (
+	"bytes"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jiajia556/godo/internal/service"
+)
+
+func openTestDatabase(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := service.OpenDatabase(&service.Config{
+		Driver: service.DriverSqlite,
+		Sqlite: service.SqliteConfig{Path: filepath.Join(t.TempDir(), "test.db")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func writeMigration(t *testing.T, dir, base, up, down string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, base+upSuffix), []byte(up), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, base+downSuffix), []byte(down), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	t.Helper()
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", name).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	return count > 0
+}
+
+func TestMigratorUpDownToAndStatus(t *testing.T) {
+	db := openTestDatabase(t)
+	dir := t.TempDir()
+	writeMigration(t, dir, "20240101000000_create_users",
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL);\n-- seed; with a comment\nINSERT INTO users (name) VALUES ('a;b');",
+		"DROP TABLE users;")
+	writeMigration(t, dir, "20240102000000_create_posts",
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY);",
+		"DROP TABLE posts;")
+	var out bytes.Buffer
+	m := newMigrator(db, dir, &out)
+
+	if err := m.up(); err != nil {
+		t.Fatalf("up() error = %v", err)
+	}
+	if !tableExists(t, db, "users") || !tableExists(t, db, "posts") {
+		t.Fatal("up() did not create tables")
+	}
+	var name string
+	if err := db.QueryRow("SELECT name FROM users").Scan(&name); err != nil || name != "a;b" {
+		t.Fatalf("seed row = %q, %v", name, err)
+	}
+	if !strings.Contains(out.String(), "applied 20240102000000_create_posts") {
+		t.Fatalf("output = %s", out.String())
+	}
+	out.Reset()
+	if err := m.up(); err != nil || !strings.Contains(out.String(), "no pending migrations") {
+		t.Fatalf("second up() = %v, output = %s", err, out.String())
+	}
+
+	if err := m.down(1); err != nil {
+		t.Fatalf("down() error = %v", err)
+	}
+	if tableExists(t, db, "posts") || !tableExists(t, db, "users") {
+		t.Fatal("down() rolled back the wrong migration")
+	}
+	statuses, err := m.status()
+	if err != nil {
+		t.Fatalf("status() error = %v", err)
+	}
+	if len(statuses) != 2 || statuses[0].state != stateApplied || statuses[1].state != statePending {
+		t.Fatalf("statuses = %+v", statuses)
+	}
+
+	if err := m.to(20240102000000); err != nil || !tableExists(t, db, "posts") {
+		t.Fatalf("to(latest) error = %v", err)
+	}
+	if err := m.to(0); err != nil || tableExists(t, db, "users") || tableExists(t, db, "posts") {
+		t.Fatalf("to(0) error = %v", err)
+	}
+	if err := m.to(20230101000000); err == nil || !strings.Contains(err.Error(), "unknown migration version") {
+		t.Fatalf("to(unknown) error = %v", err)
+	}
+}
+
+func TestMigratorDetectsChecksumMismatchAndFailures(t *testing.T) {
+	db := openTestDatabase(t)
+	dir := t.TempDir()
+	writeMigration(t, dir, "20240101000000_create_users", "CREATE TABLE users (id INTEGER PRIMARY KEY);", "DROP TABLE users;")
+	m := newMigrator(db, dir, &bytes.Buffer{})
+	if err := m.up(); err != nil {
+		t.Fatal(err)
+	}
+
+	writeMigration(t, dir, "20240101000000_create_users", "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);", "DROP TABLE users;")
+	if err := m.up(); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("up() after edit error = %v", err)
+	}
+	statuses, err := m.status()
+	if err != nil || len(statuses) != 1 || statuses[0].state != stateModified {
+		t.Fatalf("status() = %+v, %v", statuses, err)
+	}
+
+	dir = t.TempDir()
+	writeMigration(t, dir, "20240201000000_broken", "CREATE TABLE broken (id INTEGER);\nNOT VALID SQL;", "DROP TABLE broken;")
+	m = newMigrator(openTestDatabase(t), dir, &bytes.Buffer{})
+	if err := m.up(); err == nil || !strings.Contains(err.Error(), "apply migration 20240201000000_broken") {
+		t.Fatalf("up(broken) error = %v", err)
+	}
+	statuses, err = m.status()
+	if err != nil || len(statuses) != 1 || statuses[0].state != statePending {
+		t.Fatalf("failed migration was recorded: %+v, %v", statuses, err)
+	}
+}
+
+func TestMigratorLockRow(t *testing.T) {
+	db := openTestDatabase(t)
+	m := newMigrator(db, t.TempDir(), &bytes.Buffer{})
+	if err := m.ensureTable(); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.lock(); err != nil {
+		t.Fatalf("lock() error = %v", err)
+	}
+	if err := m.up(); err == nil || !strings.Contains(err.Error(), "migrations are locked") {
+		t.Fatalf("up() while locked error = %v", err)
+	}
+	if err := m.unlock(); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.up(); err != nil {
+		t.Fatalf("up() after unlock error = %v", err)
+	}
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM schema_migrations").Scan(&count); err != nil || count != 0 {
+		t.Fatalf("lock row left behind: count = %d, err = %v", count, err)
+	}
+}
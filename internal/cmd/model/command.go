@@ -0,0 +1,37 @@
+package model
+
+import (
+	genmodel "github.com/jiajia556/godo/internal/cmd/gen/model"
+	"github.com/spf13/cobra"
+)
+
+var modelCmd = &cobra.Command{
+	Use:   "model",
+	Short: "Inspect database models",
+}
+
+var diffCmd = &cobra.Command{
+	Use:     "diff <config.json|schema.sql>",
+	Short:   "Report drift between the database schema and model structs",
+	Long:    "Compare every table's columns with the fields of the struct in internal/common/models/<pkg>/model.go.\nMissing, extra and mistyped fields (Go type or GORM tag) are reported, and the command exits non-zero when any drift is found.\nWith --db, the named connection of the config file is read and compared with the models in internal/common/models/<db>/<pkg>.",
+	Example: "  godo model diff schema.sql\n  godo model diff config.json\n  godo model diff config.json --db analytics",
+	Args:    cobra.ExactArgs(1),
+	// Drift is reported as an error for the exit code; the usage text would
+	// only bury the report.
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		database, _ := cmd.Flags().GetString("db")
+		prefix, _ := cmd.Flags().GetString("prefix")
+		return genmodel.DiffSchema(args[0], database, prefix, cmd.OutOrStdout())
+	},
+}
+
+func GetCommand() *cobra.Command {
+	return modelCmd
+}
+
+func init() {
+	diffCmd.Flags().String("db", "", "Named database under databases in the config file whose models to compare (defaults to the mysql connection)")
+	diffCmd.Flags().String("prefix", "", "Table prefix stripped from struct and package names (defaults to the prefix of the database in the config file)")
+	modelCmd.AddCommand(diffCmd)
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	genmodel "github.com/jiajia556/godo/internal/cmd/gen/model"
+)
+
+func TestDiffCommandReportsDriftWithoutUsage(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "godoconfig.json"), []byte(`{"project_name":"example.com/project"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GOD_PROJECT_ROOT", root)
+	schema := filepath.Join(root, "schema.sql")
+	if err := os.WriteFile(schema, []byte("CREATE TABLE `users` (`id` bigint NOT NULL, PRIMARY KEY (`id`));"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	cmd := GetCommand()
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs([]string{"diff", schema})
+	if err := cmd.Execute(); !errors.Is(err, genmodel.ErrSchemaDrift) {
+		t.Fatalf("model diff error = %v", err)
+	}
+	if !strings.Contains(out.String(), "users: model file not found") || strings.Contains(out.String(), "Usage:") {
+		t.Fatalf("model diff output = %s", out.String())
+	}
+}
@@ -5,6 +5,9 @@ import (
 	configcmd "github.com/jiajia556/godo/internal/cmd/config"
 	"github.com/jiajia556/godo/internal/cmd/gen"
 	initproj "github.com/jiajia556/godo/internal/cmd/init"
+	"github.com/jiajia556/godo/internal/cmd/migrate"
+	modelcmd "github.com/jiajia556/godo/internal/cmd/model"
+	"github.com/jiajia556/godo/internal/cmd/seed"
 	"github.com/spf13/cobra"
 )
 
@@ -28,5 +31,8 @@ func init() {
 		gen.GetCommand(),
 		build.GetCommand(),
 		configcmd.GetCommand(),
+		modelcmd.GetCommand(),
+		migrate.GetCommand(),
+		seed.GetCommand(),
 	)
 }
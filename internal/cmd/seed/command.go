@@ -0,0 +1,33 @@
+package seed
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var seedCmd = &cobra.Command{
+	Use:   "seed",
+	Short: "Load fixture data into the database",
+	Long: "Insert the rows of the YAML and JSON fixture files in --dir, and in --dir/<env> with --env, into the database described by a config file.\n" +
+		"Each file maps table names to lists of rows keyed by column. Columns are checked against the models in internal/common/models,\n" +
+		"tables are filled after the tables their foreign keys reference, and everything runs in a single transaction.",
+	Example: "  godo seed --config config.yaml\n  godo seed --dir seeds --env dev --truncate --config config.yaml",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configPath, _ := cmd.Flags().GetString("config")
+		dir, _ := cmd.Flags().GetString("dir")
+		env, _ := cmd.Flags().GetString("env")
+		truncate, _ := cmd.Flags().GetBool("truncate")
+		return seed(configPath, dir, env, truncate, cmd.OutOrStdout())
+	},
+}
+
+func GetCommand() *cobra.Command {
+	return seedCmd
+}
+
+func init() {
+	seedCmd.Flags().StringP("config", "c", "", "Database config file (the same format used by 'godo gen model')")
+	seedCmd.Flags().StringP("dir", "", "seeds", "Directory holding fixture files, relative to the project root")
+	seedCmd.Flags().StringP("env", "e", "", "Also load the fixture files in the <dir>/<env> subdirectory")
+	seedCmd.Flags().BoolP("truncate", "t", false, "Delete the existing rows of the seeded tables first")
+}
@@ -0,0 +1,253 @@
+package seed
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	genmodel "github.com/jiajia556/godo/internal/cmd/gen/model"
+	"gopkg.in/yaml.v3"
+)
+
+// fixture holds the rows of a table read from the fixture files and the model
+// declaring the table.
+type fixture struct {
+	table string
+	rows  []row
+	model *genmodel.ModelInfo
+}
+
+// row is a fixture row keyed by column. file and index locate it in errors.
+type row struct {
+	file   string
+	index  int
+	values map[string]any
+}
+
+func (r row) String() string {
+	return fmt.Sprintf("%s row %d", r.file, r.index)
+}
+
+// loadFixtures reads the fixture files directly in dir and, when env is set,
+// in dir/env. Files are read in name order and the rows of a table found in
+// several files are concatenated; tables keep the order they first appear in.
+func loadFixtures(dir, env string) ([]*fixture, error) {
+	dirs := []string{dir}
+	if env != "" {
+		if !filepath.IsLocal(env) || strings.ContainsAny(env, `/\`) {
+			return nil, fmt.Errorf("invalid environment %q", env)
+		}
+		dirs = append(dirs, filepath.Join(dir, env))
+	}
+
+	var fixtures []*fixture
+	byTable := make(map[string]*fixture)
+	for _, d := range dirs {
+		entries, err := os.ReadDir(d)
+		if err != nil {
+			return nil, fmt.Errorf("read fixture directory: %w", err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !isFixtureFile(entry.Name()) {
+				continue
+			}
+			path := filepath.Join(d, entry.Name())
+			tables, err := readFixtureFile(path)
+			if err != nil {
+				return nil, err
+			}
+			name, _ := filepath.Rel(dir, path)
+			names := make([]string, 0, len(tables))
+			for table := range tables {
+				names = append(names, table)
+			}
+			slices.Sort(names)
+			for _, table := range names {
+				f, ok := byTable[table]
+				if !ok {
+					f = &fixture{table: table}
+					byTable[table] = f
+					fixtures = append(fixtures, f)
+				}
+				for i, values := range tables[table] {
+					f.rows = append(f.rows, row{file: filepath.ToSlash(name), index: i + 1, values: values})
+				}
+			}
+		}
+	}
+	if len(fixtures) == 0 {
+		return nil, fmt.Errorf("no fixtures found in %s", strings.Join(dirs, ", "))
+	}
+	return fixtures, nil
+}
+
+func isFixtureFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// readFixtureFile reads a file mapping table names to lists of rows. JSON
+// numbers are kept as integers when they have no fraction or exponent.
+func readFixtureFile(path string) (map[string][]map[string]any, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read fixture file: %w", err)
+	}
+	var tables map[string][]map[string]any
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.UseNumber()
+		err = decoder.Decode(&tables)
+	} else {
+		err = yaml.Unmarshal(content, &tables)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse fixture file %s: %w", path, err)
+	}
+	return tables, nil
+}
+
+// resolveModels finds the model of every fixture's table and checks the rows
+// against it.
+func resolveModels(fixtures []*fixture, models []*genmodel.ModelInfo) error {
+	byTable := make(map[string]*genmodel.ModelInfo, len(models))
+	for _, model := range models {
+		byTable[model.TableName] = model
+	}
+	for _, f := range fixtures {
+		f.model = byTable[f.table]
+		if f.model == nil {
+			return fmt.Errorf("no model for table %q in internal/common/models", f.table)
+		}
+		if f.model.View {
+			return fmt.Errorf("table %q is a view and cannot be seeded", f.table)
+		}
+		for _, r := range f.rows {
+			if err := checkRow(f.model, r); err != nil {
+				return fmt.Errorf("%s %s: %w", f.table, r, err)
+			}
+		}
+	}
+	return nil
+}
+
+// checkRow reports columns the model does not declare, values its column
+// definitions reject and required columns the row leaves out.
+func checkRow(model *genmodel.ModelInfo, r row) error {
+	fields := make(map[string]genmodel.ModelField, len(model.Fields))
+	for _, field := range model.Fields {
+		fields[field.Column] = field
+	}
+	columns := make([]string, 0, len(r.values))
+	for column := range r.values {
+		columns = append(columns, column)
+	}
+	slices.Sort(columns)
+	for _, column := range columns {
+		field, ok := fields[column]
+		if !ok {
+			return fmt.Errorf("unknown column %q of model %s", column, model.StructName)
+		}
+		if err := checkValue(field, r.values[column]); err != nil {
+			return fmt.Errorf("column %q: %w", column, err)
+		}
+	}
+	for _, field := range model.Fields {
+		if _, ok := r.values[field.Column]; !ok && isRequired(field) {
+			return fmt.Errorf("missing required column %q", field.Column)
+		}
+	}
+	return nil
+}
+
+// isRequired reports whether inserting a row needs a value for field.
+func isRequired(field genmodel.ModelField) bool {
+	return field.NotNull && !field.HasDefault && !field.AutoIncrement && !field.Managed
+}
+
+func checkValue(field genmodel.ModelField, value any) error {
+	if value == nil {
+		if field.NotNull {
+			return fmt.Errorf("column is NOT NULL")
+		}
+		return nil
+	}
+	if len(field.Enum) > 0 {
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("value %v is not a string", value)
+		}
+		values := []string{s}
+		if field.Set {
+			values = strings.Split(s, ",")
+			if s == "" {
+				values = nil
+			}
+		}
+		for _, v := range values {
+			if !slices.Contains(field.Enum, v) {
+				return fmt.Errorf("value %q is not one of %s", v, strings.Join(field.Enum, ", "))
+			}
+		}
+	}
+	if s, ok := value.(string); ok && field.MaxLength > 0 && utf8.RuneCountInString(s) > field.MaxLength {
+		return fmt.Errorf("value is longer than %d characters", field.MaxLength)
+	}
+	return nil
+}
+
+// orderFixtures sorts fixtures so that every table comes after the tables its
+// foreign keys reference, keeping the given order otherwise. References to
+// tables without fixtures and to the table itself are ignored.
+func orderFixtures(fixtures []*fixture) ([]*fixture, error) {
+	seeded := make(map[string]bool, len(fixtures))
+	for _, f := range fixtures {
+		seeded[f.table] = false
+	}
+	deps := make(map[string][]string, len(fixtures))
+	for _, f := range fixtures {
+		if f.model.CreateDDL == "" {
+			continue
+		}
+		table, err := genmodel.ParseCreateTable(f.model.CreateDDL)
+		if err != nil {
+			return nil, fmt.Errorf("parse create DDL of %s: %w", f.model.StructName, err)
+		}
+		for _, key := range table.ForeignKeys() {
+			if _, ok := seeded[key.RefTable]; ok && key.RefTable != f.table {
+				deps[f.table] = append(deps[f.table], key.RefTable)
+			}
+		}
+	}
+
+	ordered := make([]*fixture, 0, len(fixtures))
+	for len(ordered) < len(fixtures) {
+		next := -1
+		for i, f := range fixtures {
+			if !seeded[f.table] && !slices.ContainsFunc(deps[f.table], func(dep string) bool { return !seeded[dep] }) {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			var cycle []string
+			for _, f := range fixtures {
+				if !seeded[f.table] {
+					cycle = append(cycle, f.table)
+				}
+			}
+			return nil, fmt.Errorf("foreign keys between tables %s form a cycle", strings.Join(cycle, ", "))
+		}
+		seeded[fixtures[next].table] = true
+		ordered = append(ordered, fixtures[next])
+	}
+	return ordered, nil
+}
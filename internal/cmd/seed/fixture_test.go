@@ -0,0 +1,136 @@
+package seed
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	genmodel "github.com/jiajia556/godo/internal/cmd/gen/model"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadFixturesMergesFilesAndEnvironment(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "01_users.yaml"), "users:\n  - {id: 1, name: alice}\nposts:\n  - {id: 1, user_id: 1}\n")
+	writeFile(t, filepath.Join(dir, "02_more.json"), `{"users": [{"id": 2, "name": "bob", "tags": ["a"]}]}`)
+	writeFile(t, filepath.Join(dir, "notes.txt"), "ignored")
+	writeFile(t, filepath.Join(dir, "dev", "users.yml"), "users:\n  - {id: 3, name: carol}\n")
+	writeFile(t, filepath.Join(dir, "test", "users.yml"), "users:\n  - {id: 4, name: dave}\n")
+
+	fixtures, err := loadFixtures(dir, "dev")
+	if err != nil {
+		t.Fatalf("loadFixtures() error = %v", err)
+	}
+	if len(fixtures) != 2 || fixtures[0].table != "posts" || fixtures[1].table != "users" {
+		t.Fatalf("fixtures = %+v", fixtures)
+	}
+	users := fixtures[1].rows
+	if len(users) != 3 || users[0].values["name"] != "alice" || users[2].values["name"] != "carol" {
+		t.Fatalf("users = %+v", users)
+	}
+	if users[1].String() != "02_more.json row 1" || users[2].String() != "dev/users.yml row 1" {
+		t.Fatalf("row locations = %s, %s", users[1], users[2])
+	}
+	if id, ok := users[1].values["id"].(json.Number); !ok || id != "2" {
+		t.Fatalf("JSON id = %#v", users[1].values["id"])
+	}
+
+	if fixtures, err := loadFixtures(dir, ""); err != nil || len(fixtures[1].rows) != 2 {
+		t.Fatalf("loadFixtures() without env = %+v, %v", fixtures, err)
+	}
+	if _, err := loadFixtures(dir, "staging"); err == nil {
+		t.Fatal("loadFixtures() succeeded for a missing environment")
+	}
+	if _, err := loadFixtures(dir, "../dev"); err == nil || !strings.Contains(err.Error(), "invalid environment") {
+		t.Fatalf("loadFixtures(../dev) error = %v", err)
+	}
+	if _, err := loadFixtures(t.TempDir(), ""); err == nil || !strings.Contains(err.Error(), "no fixtures") {
+		t.Fatalf("loadFixtures(empty) error = %v", err)
+	}
+}
+
+var testUserModel = &genmodel.ModelInfo{
+	StructName: "Users",
+	TableName:  "users",
+	Fields: []genmodel.ModelField{
+		{Name: "Id", Type: "uint64", Column: "id", PrimaryKey: true, AutoIncrement: true, NotNull: true},
+		{Name: "Name", Type: "string", Column: "name", NotNull: true, MaxLength: 5},
+		{Name: "Status", Type: "users.UsersStatus", Column: "status", NotNull: true, HasDefault: true, Enum: []string{"active", "banned"}},
+		{Name: "Tags", Type: "users.UsersTagsSet", Column: "tags", Enum: []string{"a", "b"}, Set: true},
+		{Name: "CreatedAt", Type: "time.Time", Column: "created_at", Managed: true, AutoTime: true},
+	},
+}
+
+func TestResolveModelsChecksRows(t *testing.T) {
+	tests := []struct {
+		values map[string]any
+		want   string
+	}{
+		{map[string]any{"name": "alice", "status": "active", "tags": "a,b"}, ""},
+		{map[string]any{"name": "alice", "nmae": "x"}, `unknown column "nmae" of model Users`},
+		{map[string]any{"status": "active"}, `missing required column "name"`},
+		{map[string]any{"name": nil}, `column "name": column is NOT NULL`},
+		{map[string]any{"name": "alexandra"}, "longer than 5 characters"},
+		{map[string]any{"name": "bob", "status": "gone"}, `value "gone" is not one of active, banned`},
+		{map[string]any{"name": "bob", "tags": "a,c"}, `value "c" is not one of a, b`},
+	}
+	for _, tt := range tests {
+		fixtures := []*fixture{{table: "users", rows: []row{{file: "users.yaml", index: 1, values: tt.values}}}}
+		err := resolveModels(fixtures, []*genmodel.ModelInfo{testUserModel})
+		if tt.want == "" {
+			if err != nil || fixtures[0].model != testUserModel {
+				t.Fatalf("resolveModels(%v) = %v", tt.values, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.want) || !strings.HasPrefix(err.Error(), "users users.yaml row 1: ") {
+			t.Fatalf("resolveModels(%v) error = %v, want %q", tt.values, err, tt.want)
+		}
+	}
+
+	fixtures := []*fixture{{table: "orders"}}
+	if err := resolveModels(fixtures, []*genmodel.ModelInfo{testUserModel}); err == nil || !strings.Contains(err.Error(), `no model for table "orders"`) {
+		t.Fatalf("resolveModels(orders) error = %v", err)
+	}
+}
+
+func TestOrderFixturesFollowsForeignKeys(t *testing.T) {
+	model := func(table, constraints string) *genmodel.ModelInfo {
+		return &genmodel.ModelInfo{StructName: table, TableName: table,
+			CreateDDL: "CREATE TABLE `" + table + "` (`id` bigint NOT NULL, `parent_id` bigint, `user_id` bigint, `post_id` bigint" + constraints + ")"}
+	}
+	comments := &fixture{table: "comments", model: model("comments",
+		", FOREIGN KEY (`post_id`) REFERENCES `posts` (`id`), FOREIGN KEY (`parent_id`) REFERENCES `comments` (`id`)")}
+	posts := &fixture{table: "posts", model: model("posts", ", CONSTRAINT `fk_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)")}
+	users := &fixture{table: "users", model: model("users", ", FOREIGN KEY (`parent_id`) REFERENCES `teams` (`id`)")}
+	tags := &fixture{table: "tags", model: &genmodel.ModelInfo{StructName: "Tags"}}
+
+	ordered, err := orderFixtures([]*fixture{comments, posts, tags, users})
+	if err != nil {
+		t.Fatalf("orderFixtures() error = %v", err)
+	}
+	var tables []string
+	for _, f := range ordered {
+		tables = append(tables, f.table)
+	}
+	if got := strings.Join(tables, ","); got != "tags,users,posts,comments" {
+		t.Fatalf("order = %s", got)
+	}
+
+	a := &fixture{table: "a", model: model("a", ", FOREIGN KEY (`user_id`) REFERENCES `b` (`id`)")}
+	b := &fixture{table: "b", model: model("b", ", FOREIGN KEY (`user_id`) REFERENCES `a` (`id`)")}
+	if _, err := orderFixtures([]*fixture{a, b, tags}); err == nil || !strings.Contains(err.Error(), "tables a, b form a cycle") {
+		t.Fatalf("orderFixtures(cycle) error = %v", err)
+	}
+}
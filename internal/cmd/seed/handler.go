@@ -0,0 +1,51 @@
+package seed
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	genmodel "github.com/jiajia556/godo/internal/cmd/gen/model"
+	"github.com/jiajia556/godo/internal/service"
+)
+
+func seed(configPath, dir, env string, truncate bool, out io.Writer) (err error) {
+	if strings.TrimSpace(configPath) == "" {
+		return fmt.Errorf("database config file is required; pass --config")
+	}
+	if strings.TrimSpace(dir) == "" {
+		return fmt.Errorf("fixture directory is empty")
+	}
+	dir, err = service.GetAbsPath(dir)
+	if err != nil {
+		return fmt.Errorf("resolve fixture directory: %w", err)
+	}
+	fixtures, err := loadFixtures(dir, env)
+	if err != nil {
+		return err
+	}
+	models, err := genmodel.LoadModelInfos()
+	if err != nil {
+		return err
+	}
+	if err := resolveModels(fixtures, models); err != nil {
+		return err
+	}
+	if fixtures, err = orderFixtures(fixtures); err != nil {
+		return err
+	}
+
+	if err := service.LoadConfig(configPath); err != nil {
+		return err
+	}
+	db, err := service.OpenDatabase(service.GetConfig())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := db.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("close database: %w", closeErr)
+		}
+	}()
+	return newSeeder(db, out).run(fixtures, truncate)
+}
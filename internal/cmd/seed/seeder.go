@@ -0,0 +1,129 @@
+package seed
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	genmodel "github.com/jiajia556/godo/internal/cmd/gen/model"
+)
+
+// seeder inserts fixtures into db.
+type seeder struct {
+	db  *sql.DB
+	out io.Writer
+	now func() time.Time
+}
+
+func newSeeder(db *sql.DB, out io.Writer) *seeder {
+	return &seeder{db: db, out: out, now: time.Now}
+}
+
+// run inserts the rows of fixtures, in order, in a single transaction. With
+// truncate, the rows of the tables are deleted first, in reverse order; DELETE
+// is used instead of TRUNCATE TABLE so that the transaction covers it.
+func (s *seeder) run(fixtures []*fixture, truncate bool) (err error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); !errors.Is(rollbackErr, sql.ErrTxDone) {
+				err = errors.Join(err, rollbackErr)
+			}
+		}
+	}()
+
+	if truncate {
+		for i := len(fixtures) - 1; i >= 0; i-- {
+			table := fixtures[i].table
+			result, err := tx.Exec("DELETE FROM " + genmodel.QuoteIdentifier(table))
+			if err != nil {
+				return fmt.Errorf("delete rows of %s: %w", table, err)
+			}
+			deleted, _ := result.RowsAffected()
+			fmt.Fprintf(s.out, "deleted %d rows from %s\n", deleted, table)
+		}
+	}
+	now := s.now()
+	for _, f := range fixtures {
+		for _, r := range f.rows {
+			if err := insertRow(tx, f, r, now); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(s.out, "seeded %d rows into %s\n", len(f.rows), f.table)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// insertRow inserts r, in model field order, setting the creation and update
+// times it leaves out to now as GORM would.
+func insertRow(tx *sql.Tx, f *fixture, r row, now time.Time) error {
+	var columns, placeholders []string
+	var args []any
+	for _, field := range f.model.Fields {
+		value, ok := r.values[field.Column]
+		if !ok {
+			if !field.AutoTime {
+				continue
+			}
+			value = autoTime(field, now)
+		}
+		value, err := sqlValue(value)
+		if err != nil {
+			return fmt.Errorf("%s %s: column %q: %w", f.table, r, field.Column, err)
+		}
+		columns = append(columns, genmodel.QuoteIdentifier(field.Column))
+		placeholders = append(placeholders, "?")
+		args = append(args, value)
+	}
+	statement := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		genmodel.QuoteIdentifier(f.table), strings.Join(columns, ", "), strings.Join(placeholders, ", "))
+	if _, err := tx.Exec(statement, args...); err != nil {
+		return fmt.Errorf("insert %s %s: %w", f.table, r, err)
+	}
+	return nil
+}
+
+// autoTime returns now in the representation of an autoCreateTime or
+// autoUpdateTime field.
+func autoTime(field genmodel.ModelField, now time.Time) any {
+	if strings.HasSuffix(field.Type, "time.Time") {
+		return now
+	}
+	switch field.AutoTimeUnit {
+	case "milli":
+		return now.UnixMilli()
+	case "nano":
+		return now.UnixNano()
+	}
+	return now.Unix()
+}
+
+// sqlValue converts a decoded fixture value to a driver argument: JSON numbers
+// become integers or floats, and maps and lists are encoded as JSON.
+func sqlValue(value any) (any, error) {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i, nil
+		}
+		return v.Float64()
+	case map[string]any, []any:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return string(encoded), nil
+	}
+	return value, nil
+}
@@ -0,0 +1,126 @@
+package seed
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	genmodel "github.com/jiajia556/godo/internal/cmd/gen/model"
+	"github.com/jiajia556/godo/internal/service"
+)
+
+func openTestDatabase(t *testing.T, path string) *sql.DB {
+	t.Helper()
+	db, err := service.OpenDatabase(&service.Config{
+		Driver: service.DriverSqlite,
+		Sqlite: service.SqliteConfig{Path: path},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func TestSeederInsertsTruncatesAndRollsBack(t *testing.T) {
+	db := openTestDatabase(t, filepath.Join(t.TempDir(), "test.db"))
+	if _, err := db.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL, profile TEXT, created_at INTEGER)"); err != nil {
+		t.Fatal(err)
+	}
+	model := &genmodel.ModelInfo{StructName: "Users", TableName: "users", Fields: []genmodel.ModelField{
+		{Name: "Id", Type: "uint64", Column: "id", PrimaryKey: true},
+		{Name: "Name", Type: "string", Column: "name", NotNull: true},
+		{Name: "Profile", Type: "datatypes.JSON", Column: "profile"},
+		{Name: "CreatedAt", Type: "int64", Column: "created_at", Managed: true, AutoTime: true, AutoTimeUnit: "milli"},
+	}}
+	users := &fixture{table: "users", model: model, rows: []row{
+		{file: "users.json", index: 1, values: map[string]any{"id": json.Number("1"), "name": "alice", "profile": map[string]any{"age": 30}}},
+		{file: "users.json", index: 2, values: map[string]any{"id": json.Number("2"), "name": "bob", "created_at": 5}},
+	}}
+	var out bytes.Buffer
+	s := newSeeder(db, &out)
+	now := time.UnixMilli(1700000000123)
+	s.now = func() time.Time { return now }
+
+	if err := s.run([]*fixture{users}, false); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	var profile string
+	var createdAt int64
+	if err := db.QueryRow("SELECT profile, created_at FROM users WHERE id = 1").Scan(&profile, &createdAt); err != nil {
+		t.Fatal(err)
+	}
+	if profile != `{"age":30}` || createdAt != now.UnixMilli() {
+		t.Fatalf("row 1 = %s, %d", profile, createdAt)
+	}
+	if err := db.QueryRow("SELECT created_at FROM users WHERE id = 2").Scan(&createdAt); err != nil || createdAt != 5 {
+		t.Fatalf("row 2 created_at = %d, %v", createdAt, err)
+	}
+	if out.String() != "seeded 2 rows into users\n" {
+		t.Fatalf("output = %q", out.String())
+	}
+
+	if err := s.run([]*fixture{users}, false); err == nil || !strings.Contains(err.Error(), "insert users users.json row 1") {
+		t.Fatalf("run() with duplicate keys error = %v", err)
+	}
+
+	out.Reset()
+	users.rows = users.rows[:1]
+	if err := s.run([]*fixture{users}, true); err != nil {
+		t.Fatalf("run(truncate) error = %v", err)
+	}
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM users").Scan(&count); err != nil || count != 1 {
+		t.Fatalf("count = %d, %v", count, err)
+	}
+	if out.String() != "deleted 2 rows from users\nseeded 1 rows into users\n" {
+		t.Fatalf("output = %q", out.String())
+	}
+
+	users.rows = append(users.rows, row{file: "users.json", index: 2, values: map[string]any{"id": 3}})
+	if err := s.run([]*fixture{users}, true); err == nil {
+		t.Fatal("run() succeeded for a row violating NOT NULL")
+	}
+	if err := db.QueryRow("SELECT COUNT(*) FROM users").Scan(&count); err != nil || count != 1 {
+		t.Fatalf("count after rollback = %d, %v", count, err)
+	}
+}
+
+func TestSeedLoadsModelsAndFixturesOfTheProject(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("GOD_PROJECT_ROOT", root)
+	writeFile(t, filepath.Join(root, "godoconfig.json"), `{"project_name":"example.com/project"}`)
+	writeFile(t, filepath.Join(root, "internal/common/models/users/model.go"), "package users\n\n"+
+		"type Users struct {\n\tId   uint64 `gorm:\"column:id;primaryKey\"`\n\tName string `gorm:\"column:name;notNull\"`\n}\n\n"+
+		"func (data *Users) TableName() string {\n\treturn \"users\"\n}\n")
+	writeFile(t, filepath.Join(root, "internal/common/models/posts/model.go"), "package posts\n\n"+
+		"type Posts struct {\n\tId     uint64 `gorm:\"column:id;primaryKey\"`\n\tUserId uint64 `gorm:\"column:user_id;notNull\"`\n}\n\n"+
+		"func (data *Posts) TableName() string {\n\treturn \"posts\"\n}\n\n"+
+		"func (data *Posts) GetCreateDDL() string {\n\treturn \"CREATE TABLE `posts` (`id` bigint NOT NULL, `user_id` bigint NOT NULL, "+
+		"PRIMARY KEY (`id`), FOREIGN KEY (`user_id`) REFERENCES `users` (`id`))\"\n}\n")
+	writeFile(t, filepath.Join(root, "seeds", "data.yaml"), "posts:\n  - {id: 10, user_id: 1}\nusers:\n  - {id: 1, name: alice}\n")
+	dbPath := filepath.Join(root, "test.db")
+	config := filepath.Join(root, "config.yaml")
+	writeFile(t, config, "driver: sqlite\nsqlite:\n  path: "+dbPath+"\n")
+
+	db := openTestDatabase(t, dbPath)
+	if _, err := db.Exec("PRAGMA foreign_keys = ON; CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL); " +
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY, user_id INTEGER NOT NULL REFERENCES users (id))"); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := seed(config, "seeds", "", false, &out); err != nil {
+		t.Fatalf("seed() error = %v", err)
+	}
+	if out.String() != "seeded 1 rows into users\nseeded 1 rows into posts\n" {
+		t.Fatalf("output = %q", out.String())
+	}
+	if err := seed("", "seeds", "", false, &out); err == nil || !strings.Contains(err.Error(), "pass --config") {
+		t.Fatalf("seed() without config error = %v", err)
+	}
+}
@@ -105,10 +105,50 @@ type MysqlConfig struct {
 	Port     int    `json:"port" yaml:"port"`
 	Prefix   string `json:"prefix" yaml:"prefix"`
 	Charset  string `json:"charset" yaml:"charset"`
+	// Socket is the path of a unix socket to connect through instead of
+	// Host and Port.
+	Socket string `json:"socket" yaml:"socket"`
+	// Schema is the schema gen model reads tables from when it differs from
+	// DBName, the default schema of the connection.
+	Schema string `json:"schema" yaml:"schema"`
+	// TLS is the go-sql-driver/mysql tls mode: true, false, skip-verify or
+	// preferred. TLSCA, TLSCert and TLSKey are PEM files of a custom CA and
+	// client certificate; setting any of them enables TLS.
+	TLS     string `json:"tls" yaml:"tls"`
+	TLSCA   string `json:"tls_ca" yaml:"tls_ca"`
+	TLSCert string `json:"tls_cert" yaml:"tls_cert"`
+	TLSKey  string `json:"tls_key" yaml:"tls_key"`
+	// Timeout, ReadTimeout and WriteTimeout are durations such as "5s" for
+	// dialing, reading and writing.
+	Timeout      string `json:"timeout" yaml:"timeout"`
+	ReadTimeout  string `json:"read_timeout" yaml:"read_timeout"`
+	WriteTimeout string `json:"write_timeout" yaml:"write_timeout"`
+}
+
+type SqliteConfig struct {
+	Path string `json:"path" yaml:"path"`
 }
 
 type Config struct {
-	Mysql MysqlConfig `json:"mysql" yaml:"mysql"`
+	// Driver selects the database used by migrations: "mysql" (default) or "sqlite".
+	Driver string      `json:"driver" yaml:"driver"`
+	Mysql  MysqlConfig `json:"mysql" yaml:"mysql"`
+	// Databases holds named MySQL connections besides the default one, as
+	// selected by gen model --db.
+	Databases map[string]MysqlConfig `json:"databases" yaml:"databases"`
+	Sqlite    SqliteConfig           `json:"sqlite" yaml:"sqlite"`
+}
+
+// MysqlFor returns the named MySQL connection, or the default one for "".
+func (c *Config) MysqlFor(name string) (MysqlConfig, error) {
+	if name == "" {
+		return c.Mysql, nil
+	}
+	mysql, ok := c.Databases[name]
+	if !ok {
+		return MysqlConfig{}, fmt.Errorf("database %q is not configured under databases", name)
+	}
+	return mysql, nil
 }
 
 var cfg *ConfigManager[Config]
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	_ "modernc.org/sqlite"
+)
+
+const (
+	DriverMysql  = "mysql"
+	DriverSqlite = "sqlite"
+)
+
+// tlsConfigName is the name the TLS config built from the TLS files of a
+// MysqlConfig is registered under.
+const tlsConfigName = "godo"
+
+// DSN returns the go-sql-driver/mysql data source name for the config. It
+// connects through Socket when set and Host:Port otherwise, and uses Charset,
+// utf8mb4 by default. TLS files are loaded and registered with the driver.
+func (c MysqlConfig) DSN() (string, error) {
+	config := mysql.NewConfig()
+	config.User = c.User
+	config.Passwd = c.Password
+	config.DBName = c.DBName
+	if c.Socket != "" {
+		config.Net = "unix"
+		config.Addr = c.Socket
+	} else {
+		port := c.Port
+		if port == 0 {
+			port = 3306
+		}
+		config.Net = "tcp"
+		config.Addr = net.JoinHostPort(c.Host, strconv.Itoa(port))
+	}
+	charset := c.Charset
+	if charset == "" {
+		charset = "utf8mb4"
+	}
+	if err := config.Apply(mysql.Charset(charset, "")); err != nil {
+		return "", err
+	}
+	config.ParseTime = true
+	config.Loc = time.Local
+
+	timeouts := []struct {
+		name  string
+		value string
+		dst   *time.Duration
+	}{
+		{"timeout", c.Timeout, &config.Timeout},
+		{"read_timeout", c.ReadTimeout, &config.ReadTimeout},
+		{"write_timeout", c.WriteTimeout, &config.WriteTimeout},
+	}
+	for _, timeout := range timeouts {
+		if timeout.value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(timeout.value)
+		if err != nil {
+			return "", fmt.Errorf("parse %s: %w", timeout.name, err)
+		}
+		*timeout.dst = d
+	}
+
+	var err error
+	if config.TLSConfig, err = c.tlsConfig(); err != nil {
+		return "", err
+	}
+	return config.FormatDSN(), nil
+}
+
+// tlsConfig returns the tls parameter of the DSN, registering a TLS config
+// when the config names a CA or client certificate.
+func (c MysqlConfig) tlsConfig() (string, error) {
+	switch c.TLS {
+	case "", "true", "false", "skip-verify", "preferred":
+	default:
+		return "", fmt.Errorf("invalid tls mode %q; expected true, false, skip-verify or preferred", c.TLS)
+	}
+	if c.TLSCA == "" && c.TLSCert == "" && c.TLSKey == "" {
+		return c.TLS, nil
+	}
+	if c.TLS == "false" {
+		return "", fmt.Errorf("tls is false but tls_ca, tls_cert or tls_key is set")
+	}
+
+	config := &tls.Config{InsecureSkipVerify: c.TLS == "skip-verify"}
+	if c.Socket == "" {
+		config.ServerName = c.Host
+	}
+	if c.TLSCA != "" {
+		pem, err := os.ReadFile(c.TLSCA)
+		if err != nil {
+			return "", fmt.Errorf("read tls_ca: %w", err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return "", fmt.Errorf("tls_ca %s contains no PEM certificates", c.TLSCA)
+		}
+	}
+	if c.TLSCert != "" || c.TLSKey != "" {
+		if c.TLSCert == "" || c.TLSKey == "" {
+			return "", fmt.Errorf("tls_cert and tls_key must be set together")
+		}
+		certificate, err := tls.LoadX509KeyPair(c.TLSCert, c.TLSKey)
+		if err != nil {
+			return "", fmt.Errorf("load tls_cert and tls_key: %w", err)
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+	if err := mysql.RegisterTLSConfig(tlsConfigName, config); err != nil {
+		return "", fmt.Errorf("register TLS config: %w", err)
+	}
+	return tlsConfigName, nil
+}
+
+// DriverName returns the normalized database driver, defaulting to MySQL.
+func (c *Config) DriverName() (string, error) {
+	driver := strings.ToLower(strings.TrimSpace(c.Driver))
+	switch driver {
+	case "", DriverMysql:
+		return DriverMysql, nil
+	case DriverSqlite, "sqlite3":
+		return DriverSqlite, nil
+	default:
+		return "", fmt.Errorf("unsupported database driver %q; expected mysql or sqlite", c.Driver)
+	}
+}
+
+// OpenDatabase opens and pings the database described by the config.
+func OpenDatabase(c *Config) (*sql.DB, error) {
+	driver, err := c.DriverName()
+	if err != nil {
+		return nil, err
+	}
+	if driver == DriverMysql {
+		dsn, err := c.Mysql.DSN()
+		if err != nil {
+			return nil, fmt.Errorf("build mysql DSN: %w", err)
+		}
+		return OpenMysql(dsn)
+	}
+
+	if strings.TrimSpace(c.Sqlite.Path) == "" {
+		return nil, fmt.Errorf("sqlite.path is empty")
+	}
+	db, err := sql.Open(driver, c.Sqlite.Path)
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	if err = db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("ping database: %w", err)
+	}
+	return db, nil
+}
+
+// OpenMysql opens and pings the MySQL database of a go-sql-driver/mysql DSN.
+// The password of the DSN is redacted from the errors it returns.
+func OpenMysql(dsn string) (*sql.DB, error) {
+	config, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		password, _ := dsnPassword(dsn)
+		return nil, RedactPassword(fmt.Errorf("parse DSN %s: %w", RedactDSN(dsn), err), password)
+	}
+	connector, err := mysql.NewConnector(config)
+	if err != nil {
+		return nil, RedactPassword(fmt.Errorf("open database: %w", err), config.Passwd)
+	}
+	db := sql.OpenDB(connector)
+	if err = db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, RedactPassword(fmt.Errorf("ping database: %w", err), config.Passwd)
+	}
+	return db, nil
+}
+
+// redacted replaces passwords in messages.
+const redacted = "****"
+
+// RedactDSN returns dsn with its password replaced by ****, for messages.
+func RedactDSN(dsn string) string {
+	start, end, ok := dsnPasswordSpan(dsn)
+	if !ok || start == end {
+		return dsn
+	}
+	return dsn[:start] + redacted + dsn[end:]
+}
+
+func dsnPassword(dsn string) (string, bool) {
+	start, end, ok := dsnPasswordSpan(dsn)
+	if !ok {
+		return "", false
+	}
+	return dsn[start:end], true
+}
+
+// dsnPasswordSpan locates the password of dsn as go-sql-driver/mysql does: the
+// user info ends at the last @ before the last /, and the password follows the
+// first : of the user info.
+func dsnPasswordSpan(dsn string) (start, end int, ok bool) {
+	slash := strings.LastIndex(dsn, "/")
+	if slash < 0 {
+		return 0, 0, false
+	}
+	at := strings.LastIndex(dsn[:slash], "@")
+	if at < 0 {
+		return 0, 0, false
+	}
+	colon := strings.Index(dsn[:at], ":")
+	if colon < 0 {
+		return 0, 0, false
+	}
+	return colon + 1, at, true
+}
+
+// RedactPassword returns err with every occurrence of password in its message
+// replaced by ****. The result still wraps err.
+func RedactPassword(err error, password string) error {
+	if err == nil || password == "" || !strings.Contains(err.Error(), password) {
+		return err
+	}
+	return &redactedError{err: err, message: strings.ReplaceAll(err.Error(), password, redacted)}
+}
+
+type redactedError struct {
+	err     error
+	message string
+}
+
+func (e *redactedError) Error() string {
+	return e.message
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigDriverNameAndDSN(t *testing.T) {
+	for driver, want := range map[string]string{"": DriverMysql, "MySQL": DriverMysql, "sqlite3": DriverSqlite} {
+		got, err := (&Config{Driver: driver}).DriverName()
+		if err != nil || got != want {
+			t.Errorf("DriverName(%q) = %q, %v; want %q", driver, got, err, want)
+		}
+	}
+	if _, err := (&Config{Driver: "oracle"}).DriverName(); err == nil {
+		t.Fatal("DriverName() accepted an unsupported driver")
+	}
+}
+
+func TestMysqlConfigDSN(t *testing.T) {
+	tests := []struct {
+		config MysqlConfig
+		want   string
+	}{
+		{MysqlConfig{Host: "db", User: "root", Password: "secret", DBName: "app", Port: 3306},
+			"root:secret@tcp(db:3306)/app?charset=utf8mb4&loc=Local&parseTime=true"},
+		{MysqlConfig{Host: "db", User: "root", DBName: "app", Charset: "latin1"},
+			"root@tcp(db:3306)/app?charset=latin1&loc=Local&parseTime=true"},
+		{MysqlConfig{Host: "db", Socket: "/run/mysqld/mysqld.sock", User: "root", DBName: "app"},
+			"root@unix(/run/mysqld/mysqld.sock)/app?charset=utf8mb4&loc=Local&parseTime=true"},
+		{MysqlConfig{Host: "db", User: "root", DBName: "app", TLS: "skip-verify", Timeout: "5s", ReadTimeout: "1m", WriteTimeout: "30s"},
+			"root@tcp(db:3306)/app?charset=utf8mb4&loc=Local&parseTime=true&readTimeout=1m0s&timeout=5s&tls=skip-verify&writeTimeout=30s"},
+	}
+	for _, tt := range tests {
+		dsn, err := tt.config.DSN()
+		if err != nil || dsn != tt.want {
+			t.Errorf("DSN(%+v) = %q, %v; want %q", tt.config, dsn, err, tt.want)
+		}
+	}
+
+	invalid := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(invalid, []byte("not a certificate"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for config, want := range map[MysqlConfig]string{
+		{Timeout: "soon"}:              "parse timeout",
+		{TLS: "always"}:                `invalid tls mode "always"`,
+		{TLSCA: invalid}:               "contains no PEM certificates",
+		{TLS: "false", TLSCA: invalid}: "tls is false",
+		{TLSCert: "client.pem"}:        "tls_cert and tls_key must be set together",
+		{TLSCA: filepath.Join(t.TempDir(), "missing.pem")}: "read tls_ca",
+	} {
+		if _, err := config.DSN(); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("DSN(%+v) error = %v, want %q", config, err, want)
+		}
+	}
+}
+
+func TestOpenMysqlRedactsThePassword(t *testing.T) {
+	if got := RedactDSN("root:p@ss/word@tcp(db:3306)/app?tls=true"); got != "root:****@tcp(db:3306)/app?tls=true" {
+		t.Fatalf("RedactDSN() = %q", got)
+	}
+	if got := RedactDSN("root@tcp(db:3306)/app"); got != "root@tcp(db:3306)/app" {
+		t.Fatalf("RedactDSN(no password) = %q", got)
+	}
+
+	for _, dsn := range []string{
+		"root:hunter2@tcp(db:3306/app",
+		"root:hunter2@tcp(127.0.0.1:1)/app?timeout=1s",
+	} {
+		db, err := OpenMysql(dsn)
+		if err == nil {
+			_ = db.Close()
+			t.Fatalf("OpenMysql(%q) succeeded", dsn)
+		}
+		if strings.Contains(err.Error(), "hunter2") {
+			t.Fatalf("OpenMysql() error = %v, contains the password", err)
+		}
+	}
+
+	err := RedactPassword(fmt.Errorf("connect: %w", os.ErrDeadlineExceeded), "connect")
+	if err.Error() != "****: i/o timeout" || !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("RedactPassword() = %v", err)
+	}
+}
+
+func TestOpenDatabaseSqlite(t *testing.T) {
+	db, err := OpenDatabase(&Config{Driver: DriverSqlite, Sqlite: SqliteConfig{Path: filepath.Join(t.TempDir(), "app.db")}})
+	if err != nil {
+		t.Fatalf("OpenDatabase() error = %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec("CREATE TABLE t (id INTEGER)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenDatabase(&Config{Driver: DriverSqlite}); err == nil || !strings.Contains(err.Error(), "sqlite.path") {
+		t.Fatalf("OpenDatabase(empty path) error = %v", err)
+	}
+}
+
+func TestConfigMysqlForSelectsNamedDatabases(t *testing.T) {
+	c := &Config{
+		Mysql:     MysqlConfig{DBName: "app"},
+		Databases: map[string]MysqlConfig{"analytics": {DBName: "events", Prefix: "ev_"}},
+	}
+	if mysql, err := c.MysqlFor(""); err != nil || mysql.DBName != "app" {
+		t.Fatalf("MysqlFor(\"\") = %+v, %v", mysql, err)
+	}
+	if mysql, err := c.MysqlFor("analytics"); err != nil || mysql.DBName != "events" || mysql.Prefix != "ev_" {
+		t.Fatalf("MysqlFor(analytics) = %+v, %v", mysql, err)
+	}
+	if _, err := c.MysqlFor("billing"); err == nil || !strings.Contains(err.Error(), `database "billing" is not configured`) {
+		t.Fatalf("MysqlFor(billing) error = %v", err)
+	}
+}
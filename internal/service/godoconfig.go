@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -18,12 +19,61 @@ type GodoConfig struct {
 	DefaultGOOS   string            `json:"default_goos"`
 	DefaultGOARCH string            `json:"default_goarch"`
 	CmdTypes      map[string]string `json:"cmd_types,omitempty"`
+	Model         *ModelConfig      `json:"model,omitempty"`
+}
+
+// ModelConfig holds the settings of gen model.
+type ModelConfig struct {
+	TypeMap TypeMap `json:"type_map,omitempty"`
+	// JSONCase is the naming strategy of JSON tags; empty means snake.
+	JSONCase string `json:"json_case,omitempty"`
+	// JSONOmitEmpty adds omitempty to the JSON tags of nullable columns.
+	JSONOmitEmpty bool `json:"json_omitempty,omitempty"`
+	// Timestamps names the columns following the GORM timestamp and soft
+	// delete conventions.
+	Timestamps *TimestampColumns `json:"timestamps,omitempty"`
+}
+
+// TimestampColumns names the creation, update and soft delete time columns.
+// Nil lists use created_at, updated_at and deleted_at; empty lists turn the
+// convention off.
+type TimestampColumns struct {
+	CreatedAt []string `json:"created_at"`
+	UpdatedAt []string `json:"updated_at"`
+	DeletedAt []string `json:"deleted_at"`
+	// UnixPrecision is the unit of integer timestamp columns: empty for
+	// seconds, "milli" or "nano".
+	UnixPrecision string `json:"unix_precision,omitempty"`
+}
+
+// TypeMap overrides the Go types of generated model fields. Columns are matched
+// by table.column first, then by column name pattern, then by SQL type.
+type TypeMap struct {
+	// Columns is keyed by "table.column".
+	Columns map[string]GoType `json:"columns,omitempty"`
+	// ColumnPatterns is keyed by a path.Match pattern on the column name, such
+	// as "*_uuid". Longer patterns are tried first.
+	ColumnPatterns map[string]GoType `json:"column_patterns,omitempty"`
+	// SQLTypes is keyed by a SQL type with or without its length and
+	// attributes, such as "json", "tinyint(1)" or "bigint unsigned".
+	SQLTypes map[string]GoType `json:"sql_types,omitempty"`
+}
+
+// GoType is a Go type and the path of the package that declares it.
+type GoType struct {
+	Type   string `json:"type"`
+	Import string `json:"import,omitempty"`
 }
 
 const (
 	CmdTypeAPI    = "api"
 	CmdTypeWorker = "worker"
 
+	JSONCaseSnake      = "snake"
+	JSONCaseCamel      = "camel"
+	JSONCaseLowerCamel = "lowerCamel"
+	JSONCaseOriginal   = "original"
+
 	ConfigKeyDefaultCmd    = "default_cmd"
 	ConfigKeyDefaultGOOS   = "default_goos"
 	ConfigKeyDefaultGOARCH = "default_goarch"
@@ -141,6 +191,148 @@ func GetProjectName() (string, error) {
 	return cfg.ProjectName, nil
 }
 
+// GetModelTypeMap returns the model.type_map section of godoconfig.json.
+func GetModelTypeMap() (TypeMap, error) {
+	cfg, _, err := getConfigState()
+	if err != nil {
+		return TypeMap{}, err
+	}
+	if cfg.Model == nil {
+		return TypeMap{}, nil
+	}
+	if err := cfg.Model.TypeMap.Validate(); err != nil {
+		return TypeMap{}, fmt.Errorf("invalid model.type_map in godoconfig.json: %w", err)
+	}
+	return cfg.Model.TypeMap, nil
+}
+
+// GetModelJSONOptions returns the JSON tag naming strategy and whether nullable
+// columns get omitempty, as saved in the model section of godoconfig.json.
+func GetModelJSONOptions() (string, bool, error) {
+	cfg, _, err := getConfigState()
+	if err != nil {
+		return "", false, err
+	}
+	if cfg.Model == nil {
+		return JSONCaseSnake, false, nil
+	}
+	jsonCase, err := NormalizeJSONCase(cfg.Model.JSONCase)
+	if err != nil {
+		return "", false, fmt.Errorf("invalid model.json_case in godoconfig.json: %w", err)
+	}
+	return jsonCase, cfg.Model.JSONOmitEmpty, nil
+}
+
+// GetModelTimestamps returns the timestamp column conventions of gen model,
+// filling the default column names.
+func GetModelTimestamps() (TimestampColumns, error) {
+	cfg, _, err := getConfigState()
+	if err != nil {
+		return TimestampColumns{}, err
+	}
+	var columns TimestampColumns
+	if cfg.Model != nil && cfg.Model.Timestamps != nil {
+		columns = *cfg.Model.Timestamps
+	}
+	if columns.CreatedAt == nil {
+		columns.CreatedAt = []string{"created_at"}
+	}
+	if columns.UpdatedAt == nil {
+		columns.UpdatedAt = []string{"updated_at"}
+	}
+	if columns.DeletedAt == nil {
+		columns.DeletedAt = []string{"deleted_at"}
+	}
+	switch columns.UnixPrecision {
+	case "", "milli", "nano":
+	default:
+		return TimestampColumns{}, fmt.Errorf("invalid model.timestamps.unix_precision %q in godoconfig.json; expected milli or nano", columns.UnixPrecision)
+	}
+	return columns, nil
+}
+
+// SetModelJSONOptions saves the JSON tag options of gen model to godoconfig.json.
+func SetModelJSONOptions(jsonCase string, omitEmpty bool) error {
+	jsonCase, err := NormalizeJSONCase(jsonCase)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !godoConfig.inited {
+		if err := initializeConfigLocked(); err != nil {
+			return err
+		}
+	}
+
+	updated := godoConfig
+	modelConfig := ModelConfig{}
+	if godoConfig.Model != nil {
+		modelConfig = *godoConfig.Model
+	}
+	modelConfig.JSONCase = jsonCase
+	modelConfig.JSONOmitEmpty = omitEmpty
+	updated.Model = &modelConfig
+	if err := writeConfigFile(filepath.Join(projectRoot, "godoconfig.json"), updated); err != nil {
+		return err
+	}
+	godoConfig = updated
+	return nil
+}
+
+// NormalizeJSONCase validates a JSON tag naming strategy, matching it
+// case-insensitively. An empty value means snake.
+func NormalizeJSONCase(jsonCase string) (string, error) {
+	jsonCase = strings.TrimSpace(jsonCase)
+	if jsonCase == "" {
+		return JSONCaseSnake, nil
+	}
+	for _, known := range []string{JSONCaseSnake, JSONCaseCamel, JSONCaseLowerCamel, JSONCaseOriginal} {
+		if strings.EqualFold(jsonCase, known) {
+			return known, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported JSON case %q; expected %s, %s, %s or %s", jsonCase, JSONCaseSnake, JSONCaseCamel, JSONCaseLowerCamel, JSONCaseOriginal)
+}
+
+// Validate checks that every entry names a type, that qualified types have an
+// import path and that keys are well-formed.
+func (m TypeMap) Validate() error {
+	for key, goType := range m.Columns {
+		if table, column, ok := strings.Cut(key, "."); !ok || table == "" || column == "" {
+			return fmt.Errorf("columns key %q is not in table.column form", key)
+		}
+		if err := goType.validate("columns", key); err != nil {
+			return err
+		}
+	}
+	for pattern, goType := range m.ColumnPatterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("column_patterns key %q: %w", pattern, err)
+		}
+		if err := goType.validate("column_patterns", pattern); err != nil {
+			return err
+		}
+	}
+	for sqlType, goType := range m.SQLTypes {
+		if err := goType.validate("sql_types", sqlType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t GoType) validate(section, key string) error {
+	if strings.TrimSpace(t.Type) == "" {
+		return fmt.Errorf("%s entry %q has no type", section, key)
+	}
+	if strings.Contains(t.Type, ".") && t.Import == "" {
+		return fmt.Errorf("%s entry %q: type %s needs an import path", section, key, t.Type)
+	}
+	return nil
+}
+
 func GetDefaultCmd() (string, error) {
 	cfg, _, err := getConfigState()
 	if err != nil {
@@ -248,6 +248,99 @@ func TestSetBuildTargetAtomically(t *testing.T) {
 	}
 }
 
+func TestGetModelTypeMap(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "godoconfig.json"), `{
+  "project_name": "example.com/project",
+  "model": {"type_map": {
+    "sql_types": {"json": {"type": "datatypes.JSON", "import": "gorm.io/datatypes"}},
+    "columns": {"users.active": {"type": "bool"}}
+  }}
+}`)
+	prepareConfigTest(t, root, root)
+
+	typeMap, err := GetModelTypeMap()
+	if err != nil {
+		t.Fatalf("GetModelTypeMap() error = %v", err)
+	}
+	if typeMap.SQLTypes["json"].Import != "gorm.io/datatypes" || typeMap.Columns["users.active"].Type != "bool" {
+		t.Fatalf("type map = %+v", typeMap)
+	}
+}
+
+func TestModelJSONOptionsArePersisted(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "godoconfig.json"), `{
+  "project_name": "example.com/project",
+  "model": {"type_map": {"columns": {"users.active": {"type": "bool"}}}}
+}`)
+	prepareConfigTest(t, root, root)
+
+	if jsonCase, omitEmpty, err := GetModelJSONOptions(); err != nil || jsonCase != JSONCaseSnake || omitEmpty {
+		t.Fatalf("GetModelJSONOptions() = %q, %v, %v", jsonCase, omitEmpty, err)
+	}
+	if err := SetModelJSONOptions("LOWERCAMEL", true); err != nil {
+		t.Fatalf("SetModelJSONOptions() error = %v", err)
+	}
+	if err := SetModelJSONOptions("kebab", false); err == nil {
+		t.Fatal("SetModelJSONOptions() accepted an unknown case")
+	}
+
+	prepareConfigTest(t, root, root)
+	jsonCase, omitEmpty, err := GetModelJSONOptions()
+	if err != nil || jsonCase != JSONCaseLowerCamel || !omitEmpty {
+		t.Fatalf("reloaded GetModelJSONOptions() = %q, %v, %v", jsonCase, omitEmpty, err)
+	}
+	typeMap, err := GetModelTypeMap()
+	if err != nil || typeMap.Columns["users.active"].Type != "bool" {
+		t.Fatalf("type map was not preserved: %+v, %v", typeMap, err)
+	}
+}
+
+func TestGetModelTimestamps(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "godoconfig.json"), `{
+  "project_name": "example.com/project",
+  "model": {"timestamps": {"created_at": ["created_on"], "deleted_at": [], "unix_precision": "milli"}}
+}`)
+	prepareConfigTest(t, root, root)
+
+	columns, err := GetModelTimestamps()
+	if err != nil {
+		t.Fatalf("GetModelTimestamps() error = %v", err)
+	}
+	if strings.Join(columns.CreatedAt, ",") != "created_on" || strings.Join(columns.UpdatedAt, ",") != "updated_at" ||
+		len(columns.DeletedAt) != 0 || columns.UnixPrecision != "milli" {
+		t.Fatalf("timestamps = %+v", columns)
+	}
+
+	writeTestFile(t, filepath.Join(root, "godoconfig.json"), `{"project_name": "example.com/project", "model": {"timestamps": {"unix_precision": "micro"}}}`)
+	prepareConfigTest(t, root, root)
+	if _, err := GetModelTimestamps(); err == nil || !strings.Contains(err.Error(), "unix_precision") {
+		t.Fatalf("GetModelTimestamps() error = %v", err)
+	}
+}
+
+func TestTypeMapValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		typeMap TypeMap
+		wantErr string
+	}{
+		{"valid", TypeMap{ColumnPatterns: map[string]GoType{"*_uuid": {Type: "uuid.UUID", Import: "github.com/google/uuid"}}}, ""},
+		{"missing import", TypeMap{SQLTypes: map[string]GoType{"json": {Type: "datatypes.JSON"}}}, "needs an import path"},
+		{"missing type", TypeMap{SQLTypes: map[string]GoType{"json": {}}}, "has no type"},
+		{"bad column key", TypeMap{Columns: map[string]GoType{"active": {Type: "bool"}}}, "table.column"},
+		{"bad pattern", TypeMap{ColumnPatterns: map[string]GoType{"[": {Type: "bool"}}}, "column_patterns"},
+	}
+	for _, tt := range tests {
+		err := tt.typeMap.Validate()
+		if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%s: Validate() error = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
 func prepareConfigTest(t *testing.T, workingDirectory, configuredRoot string) {
 	t.Helper()
 	previousDirectory, err := os.Getwd()
@@ -1,6 +1,7 @@
 package template
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -38,8 +39,96 @@ type ModelData struct {
 	ModelStructName string
 	TableName       string
 	CreateDDL       string
-	UseTime         bool
-	UseDecimal      bool
+	// Imports lists the packages of the struct's field types.
+	Imports    []string
+	Enums      []EnumData
+	UseEnumSet bool
+	// IDExpr is the expression returned by ID(): the single integer primary
+	// key converted to uint64, or 0 when the table has no such key.
+	IDExpr string
+	// PrimaryKeys lists the primary key fields in key order.
+	PrimaryKeys []KeyField
+	// KeyTypeName names the struct returned by Key() for composite keys.
+	KeyTypeName string
+	// Database names the connection of models generated with --db; empty for
+	// the default connection.
+	Database string
+	// View is set for models of database views, which are read-only.
+	View bool
+}
+
+// KeyField is a primary key column and the struct field holding it.
+type KeyField struct {
+	Column string
+	Field  string
+	Type   string
+}
+
+// EnumData describes the named type generated for an ENUM or SET column.
+type EnumData struct {
+	// TypeName is the string type holding a single value.
+	TypeName string
+	// SetTypeName is the slice type of a SET column; empty for ENUM columns.
+	SetTypeName string
+	ColumnName  string
+	Values      []EnumValue
+	// EmptyIsNull stores the empty value as NULL. It is set for nullable ENUM
+	// columns that do not allow ''.
+	EmptyIsNull bool
+}
+
+// EnumValue is an allowed value of an ENUM or SET column and its constant.
+type EnumValue struct {
+	ConstName string
+	Value     string
+}
+
+// DTOData holds data used to render the request and response structs of a
+// model.
+type DTOData struct {
+	// Name prefixes the DTO types, e.g. User for UserCreateReq.
+	Name            string
+	ModelPkg        string
+	ModelImportPath string
+	ModelStructName string
+	// Imports lists the packages used by the DTO file.
+	Imports []string
+	// KeyFields are the primary key fields and QueryFields the fields lists
+	// can be filtered by.
+	KeyFields   []DTOField
+	QueryFields []DTOField
+	// CreateFields and UpdateFields are the fields clients may set.
+	CreateFields []DTOField
+	UpdateFields []DTOField
+	RespFields   []DTOField
+}
+
+// DTOField is a field of a request or response struct.
+type DTOField struct {
+	Name   string
+	Type   string
+	JSON   string
+	Column string
+	// Binding is the value of the binding tag, if any.
+	Binding string
+	// Deref marks request fields made pointers so that binding:"required"
+	// accepts zero values; they are dereferenced when copied to the model.
+	Deref bool
+}
+
+// CrudData holds data used to render the controller, DTO and service
+// templates of a model's CRUD endpoints.
+type CrudData struct {
+	DTOData
+	ProjectName          string
+	CmdName              string
+	ControllerStructName string
+	TableName            string
+	// KeyArgs passes the key fields to Read; OrderBy sorts list pages by them
+	// unless clients sort by one of SortColumns.
+	KeyArgs     string
+	OrderBy     string
+	SortColumns []string
 }
 
 type TemplateWriter struct {
@@ -130,3 +219,17 @@ func (w *TemplateWriter) CreateFile(tmplContent string, data any, path string) e
 func CreateFile(tmplContent string, data any, path string) error {
 	return NewTemplateWriter().CreateFile(tmplContent, data, path)
 }
+
+// Render executes the provided template content with data and returns the
+// rendered output without touching the filesystem.
+func Render(tmplContent string, data any) ([]byte, error) {
+	tmpl, err := stdtmpl.New("render").Parse(tmplContent)
+	if err != nil {
+		return nil, fmt.Errorf("parse template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("execute template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -85,3 +85,13 @@ func TestTemplateWriterReportsParseExecuteAndDirectoryErrors(t *testing.T) {
 		t.Fatalf("directory error = %v", err)
 	}
 }
+
+func TestRenderReturnsOutputAndErrors(t *testing.T) {
+	content, err := Render("{{.ProjectName}}/{{.CmdName}}", ProjectNameData{ProjectName: "demo", CmdName: "api"})
+	if err != nil || string(content) != "demo/api" {
+		t.Fatalf("Render() = %q, %v", content, err)
+	}
+	if _, err := Render("{{", nil); err == nil || !strings.Contains(err.Error(), "parse template") {
+		t.Fatalf("parse error = %v", err)
+	}
+}
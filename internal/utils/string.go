@@ -32,14 +32,64 @@ func LowercaseFirstLetter(s string) string {
 	return string(unicode.ToLower(r)) + s[size:]
 }
 
-func CamelToSnake(s string) string {
-	var result []rune
-	for i, r := range s {
-		if i > 0 && r >= 'A' && r <= 'Z' {
-			result = append(result, '_')
+// SplitWords splits an identifier into words at underscores, hyphens, spaces
+// and case changes. Acronyms stay together and digits stay with the word they
+// follow: "userID" gives [user ID], "HTTPServer" gives [HTTP Server] and
+// "md5_sum" gives [md5 sum].
+func SplitWords(s string) []string {
+	var words []string
+	runes := []rune(s)
+	start := -1
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if start >= 0 {
+				words = append(words, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+			continue
+		}
+		prev := runes[i-1]
+		if unicode.IsUpper(r) {
+			lowerBefore := unicode.IsLower(prev) || unicode.IsDigit(prev)
+			acronymEnd := unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if lowerBefore || acronymEnd {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
 		}
-		result = append(result, r)
 	}
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+// CamelToSnake converts an identifier to snake_case, e.g. "userID" to "user_id".
+// Characters other than letters and digits only separate words, so the result
+// is meant for building identifiers, not for naming existing tables.
+func CamelToSnake(s string) string {
+	words := SplitWords(s)
+	for i, word := range words {
+		words[i] = strings.ToLower(word)
+	}
+	return strings.Join(words, "_")
+}
+
+// ToCamelCase converts an identifier to CamelCase, e.g. "user_id" to "UserId".
+func ToCamelCase(s string) string {
+	words := SplitWords(s)
+	for i, word := range words {
+		words[i] = CapitalizeFirstLetter(strings.ToLower(word))
+	}
+	return strings.Join(words, "")
+}
 
-	return strings.ToLower(string(result))
+// ToLowerCamelCase converts an identifier to lowerCamelCase, e.g. "user_id" to
+// "userId".
+func ToLowerCamelCase(s string) string {
+	return LowercaseFirstLetter(ToCamelCase(s))
 }
@@ -23,9 +23,12 @@ func TestFirstLetterCaseConversionSupportsUnicode(t *testing.T) {
 
 func TestCamelToSnake(t *testing.T) {
 	tests := map[string]string{
-		"UserID":     "user_i_d",
+		"UserID":     "user_id",
 		"userName":   "user_name",
-		"Already_OK": "already__o_k",
+		"Already_OK": "already_ok",
+		"HTTPServer": "http_server",
+		"md5Hash":    "md5_hash",
+		"order_2fa":  "order_2fa",
 		"":           "",
 	}
 	for input, want := range tests {
@@ -35,6 +38,27 @@ func TestCamelToSnake(t *testing.T) {
 	}
 }
 
+func TestCamelCaseConversion(t *testing.T) {
+	tests := []struct {
+		input, camel, lowerCamel string
+	}{
+		{"user_id", "UserId", "userId"},
+		{"userID", "UserId", "userId"},
+		{"created-at", "CreatedAt", "createdAt"},
+		{"HTTPServer", "HttpServer", "httpServer"},
+		{"name", "Name", "name"},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		if got := ToCamelCase(tt.input); got != tt.camel {
+			t.Errorf("ToCamelCase(%q) = %q, want %q", tt.input, got, tt.camel)
+		}
+		if got := ToLowerCamelCase(tt.input); got != tt.lowerCamel {
+			t.Errorf("ToLowerCamelCase(%q) = %q, want %q", tt.input, got, tt.lowerCamel)
+		}
+	}
+}
+
 func TestLoggerWritesExpectedLevels(t *testing.T) {
 	var output bytes.Buffer
 	logger := NewLogger(false)